@@ -4,10 +4,16 @@ import "time"
 
 type Graphics interface {
 	ClearScreen(r, g, b uint8)
+	// FillRect fills the rectangle with the color, the rectangle is in screen
+	// coordinates, not in world coordinates like images
+	FillRect(rect Rectangle, r, g, b uint8)
 }
 
 type Image interface {
 	DrawAt(x, y int)
+	// DrawTranslucentAt draws the image blended with the background, opacity
+	// is in the range [0,1] where 0 is invisible and 1 is fully opaque
+	DrawTranslucentAt(x, y int, opacity float32)
 	Size() (width, height int)
 }
 
@@ -0,0 +1,65 @@
+package main
+
+// LevelBouncePad is a TopSolid object that launches the characters that land
+// on it. SpeedY is the upward speed in SubPixels per frame, it is negative and
+// may be faster than a jump. JumpBonus is added to it when the character holds
+// jump while landing, 0 turns this off. The Image is drawn relative to the
+// pad's top-left corner and the Sound is played for every launch.
+type LevelBouncePad struct {
+	X, Y, W, H int
+	SpeedY     int
+	JumpBonus  int
+	Image      LevelImage
+	Sound      string
+}
+
+type bouncePad struct {
+	LevelBouncePad
+	image Image
+	sound Sound
+}
+
+func (g *Game) loadBouncePads(assets AssetLoader, level *Level) {
+	g.bouncePads = make([]bouncePad, len(level.BouncePads))
+	for i := range level.BouncePads {
+		p := &g.bouncePads[i]
+		p.LevelBouncePad = level.BouncePads[i]
+		if p.Image.ID != "" {
+			p.image = assets.LoadImage(p.Image.ID)
+		}
+		p.sound = assets.LoadSound(p.Sound)
+	}
+}
+
+// bounce launches the character if she stands on a bounce pad.
+func (g *Game) bounce(char *Character, jumpDown bool) {
+	if char.InAir {
+		return
+	}
+	for i := range g.bouncePads {
+		p := &g.bouncePads[i]
+		bounds := Rectangle{p.X, p.Y, p.W, p.H}
+		if standsOn(char, bounds) {
+			char.SpeedY = p.SpeedY
+			if jumpDown {
+				char.SpeedY += p.JumpBonus
+			}
+			char.subY = 0
+			char.InAir = true
+			// the ghost has no influence on the game, not even on the sound
+			if !char.ghost {
+				p.sound.PlayOnce()
+			}
+			return
+		}
+	}
+}
+
+func (g *Game) renderBouncePads() {
+	for i := range g.bouncePads {
+		p := &g.bouncePads[i]
+		if p.image != nil {
+			p.image.DrawAt(p.X+p.Image.X, p.Y+p.Image.Y)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import "sort"
+
+// CollisionGridCellSize is the width and height of the cells in the collision
+// grid. Characters move only a few pixels per frame so most queries touch a
+// single cell or two neighbouring ones.
+const CollisionGridCellSize = 256
+
+// collisionGrid is a uniform grid over the level that remembers which
+// collision objects overlap which cells. Only cells that contain objects are
+// stored so the level can extend arbitrarily in all directions.
+type collisionGrid struct {
+	cellSize int
+	cells    map[Point][]int
+	// seen is used to report every object only once per query, an object is
+	// already in the result if seen[i] == query
+	seen  []int
+	query int
+	found []int
+}
+
+func newCollisionGrid(objects []CollisionObject, cellSize int) *collisionGrid {
+	g := &collisionGrid{
+		cellSize: cellSize,
+		cells:    make(map[Point][]int),
+		seen:     make([]int, len(objects)),
+	}
+	for i := range objects {
+		g.forEachCell(objects[i].Bounds, func(cell Point) {
+			g.cells[cell] = append(g.cells[cell], i)
+		})
+	}
+	return g
+}
+
+// near returns the indices of all objects that might overlap r, in ascending
+// order. The order is the same as in the level's object list so that
+// collisions resolve exactly the same as when checking all objects. The
+// returned slice is only valid until the next call.
+func (g *collisionGrid) near(r Rectangle) []int {
+	g.query++
+	g.found = g.found[:0]
+	g.forEachCell(r, func(cell Point) {
+		for _, i := range g.cells[cell] {
+			if g.seen[i] != g.query {
+				g.seen[i] = g.query
+				g.found = append(g.found, i)
+			}
+		}
+	})
+	sort.Ints(g.found)
+	return g.found
+}
+
+// forEachCell calls f for every cell that r touches. Rectangles with a negative
+// size still overlap everything that covers their first pixel, see
+// Rectangle.Overlaps, so for them the cells between their last and first pixel
+// are visited.
+func (g *collisionGrid) forEachCell(r Rectangle, f func(cell Point)) {
+	left, top := g.cellAt(min(r.X, r.X+r.W-1), min(r.Y, r.Y+r.H-1))
+	right, bottom := g.cellAt(max(r.X, r.X+r.W-1), max(r.Y, r.Y+r.H-1))
+	for y := top; y <= bottom; y++ {
+		for x := left; x <= right; x++ {
+			f(Point{x, y})
+		}
+	}
+}
+
+// cellAt returns the cell containing pixel x,y, this rounds towards negative
+// infinity so the cells left of and above 0 are as big as all others.
+func (g *collisionGrid) cellAt(x, y int) (int, int) {
+	return floorDiv(x, g.cellSize), floorDiv(y, g.cellSize)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+// +build headless
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// oneCellGrid puts all objects into the same cell, every query returns all of
+// them, just like checking every object in a loop.
+func oneCellGrid(objects []CollisionObject) *collisionGrid {
+	return newCollisionGrid(objects, 1<<30)
+}
+
+func TestGridMovesLikeLinearScan(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	level, _ := newTestGame(0)
+	for layout := 0; layout < 1000; layout++ {
+		g := level
+		if layout > 0 {
+			g = randomCollisionLayout(r)
+		}
+		// the moving platforms come after the static objects, they are not in
+		// the grid
+		grid, linear := g.grid, oneCellGrid(g.objects[:len(g.objects)-len(g.platforms)])
+		for i := 0; i < 100; i++ {
+			bounds := Rectangle{r.Intn(11000) - 500, r.Intn(2600) - 1700, 1 + r.Intn(80), 1 + r.Intn(100)}
+			if layout > 0 {
+				bounds.X, bounds.Y = r.Intn(600)-100, r.Intn(600)-100
+			}
+			d := r.Intn(121) - 60
+
+			g.grid = grid
+			gridX, gridHitX := g.MoveInX(bounds, d)
+			gridY, gridHitY := g.MoveInY(bounds, d)
+			g.grid = linear
+			linearX, linearHitX := g.MoveInX(bounds, d)
+			linearY, linearHitY := g.MoveInY(bounds, d)
+			g.grid = grid
+
+			if gridX != linearX || gridHitX != linearHitX {
+				t.Fatalf("layout %v: MoveInX(%v, %v) is %v, %v with the grid but %v, %v without",
+					layout, bounds, d, gridX, gridHitX, linearX, linearHitX)
+			}
+			if gridY != linearY || gridHitY != linearHitY {
+				t.Fatalf("layout %v: MoveInY(%v, %v) is %v, %v with the grid but %v, %v without",
+					layout, bounds, d, gridY, gridHitY, linearY, linearHitY)
+			}
+		}
+	}
+}
+
+// bigLevel is level1 repeated 50 times side by side.
+func bigLevel() *Game {
+	g, _ := newTestGame(0)
+	const copies, levelWidth = 50, 11000
+	n := len(g.objects)
+	for i := 1; i < copies; i++ {
+		for _, o := range g.objects[:n] {
+			o.Bounds.X += i * levelWidth
+			g.objects = append(g.objects, o)
+		}
+	}
+	g.grid = newCollisionGrid(g.objects, CollisionGridCellSize)
+	return g
+}
+
+func benchmarkMove(b *testing.B, move func(g *Game, bounds Rectangle)) {
+	g := bigLevel()
+	grids := []struct {
+		name string
+		grid *collisionGrid
+	}{
+		{"grid", g.grid},
+		{"linear", oneCellGrid(g.objects)},
+	}
+	for _, grid := range grids {
+		b.Run(grid.name, func(b *testing.B) {
+			g.grid = grid.grid
+			for i := 0; i < b.N; i++ {
+				// walk along the ground of the first level copy
+				move(g, Rectangle{200 + i%2900, 480, 40, 57})
+			}
+		})
+	}
+}
+
+func BenchmarkMoveInX(b *testing.B) {
+	benchmarkMove(b, func(g *Game, bounds Rectangle) { g.MoveInX(bounds, 5) })
+}
+
+func BenchmarkMoveInY(b *testing.B) {
+	benchmarkMove(b, func(g *Game, bounds Rectangle) { g.MoveInY(bounds, 5) })
+}
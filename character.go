@@ -1,5 +1,10 @@
 package main
 
+// SubPixels is the number of fixed-point units per pixel. Character speeds and
+// all CharacterParams except the RunFrameDelay are measured in these units,
+// collision is still resolved in whole pixels.
+const SubPixels = 256
+
 type CharacterParams struct {
 	AccelerationX     int
 	DecelerationX     int
@@ -9,47 +14,110 @@ type CharacterParams struct {
 	HighGravity       int
 	LowGravity        int
 	RunFrameDelay     int
+	// the wall abilities are off if their params are 0; while in the air and
+	// pushing against a wall she slides down at most at WallSlideSpeedY and
+	// jumping pushes her off the wall with WallJumpSpeedX and WallJumpSpeedY
+	WallSlideSpeedY int
+	WallJumpSpeedX  int
+	WallJumpSpeedY  int
+	// CoyoteFrames is the number of frames after walking off a ledge in which
+	// she can still jump, JumpBufferFrames is the number of frames that a jump
+	// pressed in the air is remembered so she jumps when landing in time
+	CoyoteFrames     int
+	JumpBufferFrames int
 }
 
 var HeroParams = CharacterParams{
-	AccelerationX:     2,
-	DecelerationX:     1,
-	MaxSpeedX:         10,
-	MaxSpeedY:         32,
-	InitialJumpSpeedY: -23,
-	HighGravity:       2,
-	LowGravity:        1,
+	AccelerationX:     2 * SubPixels,
+	DecelerationX:     1 * SubPixels,
+	MaxSpeedX:         10 * SubPixels,
+	MaxSpeedY:         32 * SubPixels,
+	InitialJumpSpeedY: -23 * SubPixels,
+	HighGravity:       2 * SubPixels,
+	LowGravity:        1 * SubPixels,
 	RunFrameDelay:     3,
+	WallSlideSpeedY:   4 * SubPixels,
+	WallJumpSpeedX:    10 * SubPixels,
+	WallJumpSpeedY:    -20 * SubPixels,
+	CoyoteFrames:      5,
+	JumpBufferFrames:  5,
 }
 
 var BarneyParams = CharacterParams{
-	AccelerationX:     2,
-	DecelerationX:     1,
-	MaxSpeedX:         11,
-	MaxSpeedY:         32,
-	InitialJumpSpeedY: -25,
-	HighGravity:       2,
-	LowGravity:        1,
+	AccelerationX:     2 * SubPixels,
+	DecelerationX:     1 * SubPixels,
+	MaxSpeedX:         11 * SubPixels,
+	MaxSpeedY:         32 * SubPixels,
+	InitialJumpSpeedY: -25 * SubPixels,
+	HighGravity:       2 * SubPixels,
+	LowGravity:        1 * SubPixels,
 	RunFrameDelay:     5,
+	WallSlideSpeedY:   4 * SubPixels,
+	WallJumpSpeedX:    11 * SubPixels,
+	WallJumpSpeedY:    -22 * SubPixels,
+	// Barney's recorded run was made without coyote time and jump buffering,
+	// with them, jumps that used to be swallowed would change his run
+	CoyoteFrames:     0,
+	JumpBufferFrames: 0,
+}
+
+// pixelsToSubPixels converts params that are in whole pixels, as they were
+// before there were sub-pixels, to sub-pixels. The frame counts stay as they
+// are.
+func (p CharacterParams) pixelsToSubPixels() CharacterParams {
+	p.AccelerationX *= SubPixels
+	p.DecelerationX *= SubPixels
+	p.MaxSpeedX *= SubPixels
+	p.MaxSpeedY *= SubPixels
+	p.InitialJumpSpeedY *= SubPixels
+	p.HighGravity *= SubPixels
+	p.LowGravity *= SubPixels
+	p.WallSlideSpeedY *= SubPixels
+	p.WallJumpSpeedX *= SubPixels
+	p.WallJumpSpeedY *= SubPixels
+	return p
 }
 
 type Character struct {
 	Direction int
 	Position  Rectangle
-	SpeedX    int
-	SpeedY    int
+	// the speeds are in SubPixels per frame
+	SpeedX int
+	SpeedY int
+	// subX and subY are the fractions of a pixel, in SubPixels, that are
+	// added to the Position; they are always in the range [0, SubPixels)
+	subX, subY int
 
 	InAir bool
+	// wallSliding is set while she slides down a wall
+	wallSliding bool
+	// coyoteFrames are the frames left in which she can jump after walking off
+	// a ledge, jumpBuffer are the frames left in which a jump that was pressed
+	// too early is still done
+	coyoteFrames int
+	jumpBuffer   int
 
 	Params        CharacterParams
 	collisionRect Rectangle
+	// lastPosition is the Position before the last update, it is used for
+	// interpolating between updates when rendering
+	lastPosition Rectangle
+	// ghost characters are drawn translucent
+	ghost bool
 
 	runFrames   [DirectionCount][]Image
 	standFrames [DirectionCount]Image
 	jumpFrames  [DirectionCount]Image
+	// wallFrames are indexed by the side of the wall that she slides down
+	wallFrames [DirectionCount]Image
 
 	runFrameIndex int
 	nextRunFrame  int
+
+	// dropThrough are the objects that she falls through for dropFrames more
+	// frames, see Game.dropThroughObjects
+	dropThrough []int
+	dropFrames  int
 }
 
 func NewHero(assets AssetLoader) *Character {
@@ -79,6 +147,10 @@ func NewHero(assets AssetLoader) *Character {
 			assets.LoadImage("gophette_left_jump"),
 			assets.LoadImage("gophette_right_jump"),
 		},
+		wallFrames: [DirectionCount]Image{
+			assets.LoadImage("gophette_left_wall"),
+			assets.LoadImage("gophette_right_wall"),
+		},
 	}
 }
 
@@ -113,6 +185,10 @@ func NewBarney(assets AssetLoader) *Character {
 			assets.LoadImage("barney_left_jump"),
 			assets.LoadImage("barney_right_jump"),
 		},
+		wallFrames: [DirectionCount]Image{
+			assets.LoadImage("barney_left_wall"),
+			assets.LoadImage("barney_right_wall"),
+		},
 	}
 }
 
@@ -120,17 +196,34 @@ func (c *Character) Reset(dir int) {
 	c.Direction = dir
 	c.SpeedX = 0
 	c.SpeedY = 0
+	c.subX = 0
+	c.subY = 0
 	c.InAir = false
+	c.wallSliding = false
+	c.coyoteFrames = 0
+	c.jumpBuffer = 0
 }
 
 func (c *Character) SetBottomCenterTo(x, y int) {
 	c.Position.X = x - c.Position.W/2
 	c.Position.Y = y - c.Position.H
+	c.subX = 0
+	c.subY = 0
+	// this is a teleport, do not interpolate from the old position
+	c.lastPosition = c.Position
 }
 
-func (c *Character) Render() {
+// renderPosition is the position between the last two updates, alpha is in the
+// range [0,1], 0 meaning the last and 1 meaning the current position.
+func (c *Character) renderPosition(alpha float64) Rectangle {
+	return c.lastPosition.Interpolate(c.Position, alpha)
+}
+
+func (c *Character) Render(alpha float64) {
 	var frame Image
-	if c.InAir {
+	if c.InAir && c.wallSliding {
+		frame = c.wallFrames[c.Direction]
+	} else if c.InAir {
 		frame = c.jumpFrames[c.Direction]
 	} else if c.SpeedX == 0 {
 		frame = c.standFrames[c.Direction]
@@ -141,15 +234,21 @@ func (c *Character) Render() {
 	// the position is that of the collision rectangle, the image does not have
 	// the same size as the collision rectangle so it must be offset relative
 	// to the collision rectangle's top-left corner for drawing
-	frame.DrawAt(
-		c.Position.X-c.collisionRect.X,
-		c.Position.Y-c.collisionRect.Y,
-	)
+	pos := c.renderPosition(alpha)
+	x, y := pos.X-c.collisionRect.X, pos.Y-c.collisionRect.Y
+	if c.ghost {
+		frame.DrawTranslucentAt(x, y, GhostOpacity)
+	} else {
+		frame.DrawAt(x, y)
+	}
 }
 
 type Collider interface {
 	MoveInX(bounds Rectangle, dx int) (newBounds Rectangle, collided bool)
 	MoveInY(bounds Rectangle, dy int) (newBounds Rectangle, collided bool)
+	// SlopeSurface returns the y of the highest slope surface at column x that
+	// is in the range [top, bottom]
+	SlopeSurface(x, top, bottom int) (y int, found bool)
 }
 
 func (c *Character) Update(collider Collider) {
@@ -182,18 +281,84 @@ func (c *Character) Update(collider Collider) {
 	// then moving in Y above the platform but to the side of it
 	var collided bool
 	c.InAir = true // assume this until proven otherwise
-	c.Position, collided = collider.MoveInY(c.Position, c.SpeedY)
+	dy := subPixelMove(&c.subY, c.SpeedY)
+	c.Position, collided = collider.MoveInY(c.Position, dy)
+	if !collided && dy == 0 && c.SpeedY > 0 {
+		// with low gravity she can fall less than a pixel in a frame, she
+		// still stands on the ground then
+		_, collided = collider.MoveInY(c.Position, 1)
+	}
 	if collided {
 		if c.SpeedY > 0 {
 			// if she was going down, she now landed on the ground
 			c.InAir = false
 		}
 		c.SpeedY = 0
+		c.subY = 0
+	}
+
+	var onSlope bool
+	if !c.InAir {
+		x, bottom := c.Position.X+c.Position.W/2, c.Position.Y+c.Position.H
+		_, onSlope = collider.SlopeSurface(x, bottom, bottom)
 	}
 
 	// move in X
-	c.Position, collided = collider.MoveInX(c.Position, c.SpeedX)
+	beforeX := c.Position
+	c.Position, collided = collider.MoveInX(c.Position, subPixelMove(&c.subX, c.SpeedX))
 	if collided {
 		c.SpeedX = 0
+		c.subX = 0
+	}
+
+	// follow slopes; walking up a slope puts her feet inside it so she has
+	// to be lifted onto its surface, walking down a slope would have her
+	// fall off of it for a moment so she is pulled down onto the ground below
+	// instead
+	x, bottom := c.Position.X+c.Position.W/2, c.Position.Y+c.Position.H
+	y, found := collider.SlopeSurface(x, bottom-SlopeStep, bottom-1)
+	// walking over the top of a slope her center can pass its high end in
+	// one update, she is still lifted onto the top
+	for oldX := beforeX.X + beforeX.W/2; onSlope && !found && x != oldX; {
+		if x < oldX {
+			x++
+		} else {
+			x--
+		}
+		y, found = collider.SlopeSurface(x, bottom-SlopeStep, bottom-1)
 	}
+	if found {
+		lifted, _ := collider.MoveInY(c.Position, y-bottom)
+		if lifted.Y+lifted.H == y {
+			c.Position = lifted
+		} else {
+			// there is a ceiling above the slope, she does not fit under it
+			// so the slope stops her like a wall
+			c.Position = beforeX
+			c.SpeedX = 0
+			c.subX = 0
+		}
+		// jumping up she is lifted as well but keeps on going up
+		if c.SpeedY >= 0 {
+			c.InAir = false
+			c.SpeedY = 0
+			c.subY = 0
+		}
+	} else if c.SpeedY >= 0 && !c.InAir {
+		_, slopeBelow := collider.SlopeSurface(x, bottom+1, bottom+SlopeStep)
+		if onSlope || slopeBelow {
+			if pos, landed := collider.MoveInY(c.Position, SlopeStep); landed {
+				c.Position = pos
+			}
+		}
+	}
+}
+
+// subPixelMove adds the speed to the fraction of a pixel in sub and returns the
+// number of whole pixels to move, sub keeps the rest.
+func subPixelMove(sub *int, speed int) int {
+	total := *sub + speed
+	pixels := floorDiv(total, SubPixels)
+	*sub = total - pixels*SubPixels
+	return pixels
 }
@@ -0,0 +1,60 @@
+package main
+
+// HeadBounceSpeedY is the vertical speed, in SubPixels per frame, of a
+// character that lands on the other one's head.
+const HeadBounceSpeedY = -16 * SubPixels
+
+// collideCharacters keeps the hero and Barney from overlapping in levels with
+// CharacterCollision. A character that comes down onto the other one's head
+// bounces off of it, otherwise they push each other apart sideways. They are
+// moved like in their updates so neither is pushed into the level.
+func (g *Game) collideCharacters() {
+	a, b := g.characters[0], g.characters[1]
+	if !a.Position.Overlaps(b.Position) {
+		return
+	}
+	if cameFromAbove(a, b) {
+		g.bounceOffHead(a, b)
+	} else if cameFromAbove(b, a) {
+		g.bounceOffHead(b, a)
+	} else {
+		g.pushApart(a, b)
+	}
+}
+
+// cameFromAbove tells if top was completely above bottom before the last
+// update.
+func cameFromAbove(top, bottom *Character) bool {
+	return top.lastPosition.Y+top.lastPosition.H <= bottom.lastPosition.Y
+}
+
+func (g *Game) bounceOffHead(top, bottom *Character) {
+	dy := bottom.Position.Y - (top.Position.Y + top.Position.H)
+	top.Position, _ = g.MoveInY(top.Position, dy)
+	top.SpeedY = HeadBounceSpeedY
+	top.subY = 0
+	top.InAir = true
+}
+
+// pushApart moves both characters away from each other by half their overlap.
+// If one of them is stopped by the level, the other one moves the rest.
+func (g *Game) pushApart(a, b *Character) {
+	left, right := a, b
+	ax, _ := a.Position.Center()
+	bx, _ := b.Position.Center()
+	if bx < ax {
+		left, right = b, a
+	}
+	overlap := left.Position.X + left.Position.W - right.Position.X
+	left.Position, _ = g.MoveInX(left.Position, -overlap/2)
+	right.Position, _ = g.MoveInX(right.Position, overlap-overlap/2)
+
+	overlap = left.Position.X + left.Position.W - right.Position.X
+	if overlap > 0 {
+		right.Position, _ = g.MoveInX(right.Position, overlap)
+	}
+	overlap = left.Position.X + left.Position.W - right.Position.X
+	if overlap > 0 {
+		left.Position, _ = g.MoveInX(left.Position, -overlap)
+	}
+}
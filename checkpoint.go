@@ -0,0 +1,74 @@
+package main
+
+// RespawnMode is what happens in a level when the hero dies.
+type RespawnMode int
+
+const (
+	// RestartRace puts both characters back at the start and restarts the
+	// race, the checkpoints are not used
+	RestartRace RespawnMode = iota
+	// RespawnAtCheckpoint puts only the hero back at the last checkpoint that
+	// she touched, Barney keeps racing
+	RespawnAtCheckpoint
+)
+
+// String returns the constant's name, the level editor writes these into the
+// level files.
+func (m RespawnMode) String() string {
+	switch m {
+	case RestartRace:
+		return "RestartRace"
+	case RespawnAtCheckpoint:
+		return "RespawnAtCheckpoint"
+	default:
+		return "unknown respawn mode"
+	}
+}
+
+// LevelCheckpoint is a rectangle that saves the hero's respawn point when she
+// touches it. She respawns at the bottom center of the rectangle.
+type LevelCheckpoint struct {
+	X, Y, W, H int
+}
+
+// CheckpointHint is shown when the hero touches a new checkpoint.
+const CheckpointHint = "checkpoint"
+
+func (g *Game) loadCheckpoints(level *Level) {
+	g.respawnMode = level.Respawn
+	g.checkpoints = make([]Rectangle, len(level.Checkpoints))
+	for i, c := range level.Checkpoints {
+		g.checkpoints[i] = Rectangle{c.X, c.Y, c.W, c.H}
+	}
+}
+
+// updateCheckpoints moves the hero's respawn point to the checkpoint that she
+// touches. If she touches more than one, the last one in the level data wins.
+// Levels that restart the race do not use checkpoints.
+func (g *Game) updateCheckpoints() {
+	if g.respawnMode != RespawnAtCheckpoint {
+		return
+	}
+	hero := g.characters[0].Position
+	for _, c := range g.checkpoints {
+		if !c.Overlaps(hero) {
+			continue
+		}
+		p := Point{c.X + c.W/2, c.Y + c.H}
+		if p != g.checkpoint {
+			g.checkpoint = p
+			g.hint = CheckpointHint
+			g.hintCountDown = HintDuration
+		}
+	}
+}
+
+// respawnHero puts the hero back at her checkpoint, everything else goes on as
+// if nothing happened.
+func (g *Game) respawnHero() {
+	hero := g.characters[0]
+	hero.SetBottomCenterTo(g.checkpoint.X, g.checkpoint.Y)
+	hero.Reset(RightDirectionIndex)
+	// the ghost can only replay runs without a respawn
+	g.ghost.discardAttempt()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// LevelCollectible is an item, e.g. a Go badge, that the hero collects by
+// touching it. The item is the image with the ID, its bounds are the image's.
+type LevelCollectible struct {
+	ID   string
+	X, Y int
+}
+
+type collectible struct {
+	ImageObject
+	bounds    Rectangle
+	collected bool
+}
+
+func (g *Game) loadCollectibles(assets AssetLoader, level *Level) {
+	g.collectibles = make([]collectible, len(level.Collectibles))
+	for i, c := range level.Collectibles {
+		img := assets.LoadImage(c.ID)
+		w, h := img.Size()
+		g.collectibles[i] = collectible{
+			ImageObject: ImageObject{img, c.X, c.Y},
+			bounds:      Rectangle{c.X, c.Y, w, h},
+		}
+	}
+}
+
+// updateCollectibles collects all items that the hero touches.
+func (g *Game) updateCollectibles() {
+	hero := g.characters[0].Position
+	for i := range g.collectibles {
+		c := &g.collectibles[i]
+		if !c.collected && c.bounds.Overlaps(hero) {
+			c.collected = true
+			g.pickupSound.PlayOnce()
+		}
+	}
+}
+
+func (g *Game) resetCollectibles() {
+	for i := range g.collectibles {
+		g.collectibles[i].collected = false
+	}
+}
+
+func (g *Game) collectedCount() int {
+	n := 0
+	for i := range g.collectibles {
+		if g.collectibles[i].collected {
+			n++
+		}
+	}
+	return n
+}
+
+func (g *Game) renderCollectibles() {
+	for i := range g.collectibles {
+		if !g.collectibles[i].collected {
+			g.collectibles[i].Render()
+		}
+	}
+}
+
+// renderCollectedCount shows how many of the level's items the hero has and
+// the most she ever collected in one race.
+func (g *Game) renderCollectedCount() {
+	if len(g.collectibles) == 0 {
+		return
+	}
+	text := fmt.Sprintf("items %v/%v", g.collectedCount(), len(g.collectibles))
+	if best, ok := g.collections.bests[g.levelID]; ok {
+		text += fmt.Sprintf(" best %v", best)
+	}
+	drawText(g.graphics, text, 20, 150, hudPixelSize/2, 255, 220, 0)
+}
+
+// bestCollections maps level IDs to the most items collected in one race.
+type bestCollections map[string]int
+
+// collectionRecords keeps the best collection count per level in the user
+// config directory.
+type collectionRecords struct {
+	bests bestCollections
+	path  string
+	// saveBests is false when the race is not played by the user, e.g. when
+	// watching a replay
+	saveBests bool
+}
+
+func bestCollectionsFile() string {
+	return userConfigFile("best_collections.json")
+}
+
+func newCollectionRecords(path string) *collectionRecords {
+	r := &collectionRecords{
+		bests:     make(bestCollections),
+		path:      path,
+		saveBests: true,
+	}
+	if path == "" {
+		return r
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return r
+	}
+	if err := json.Unmarshal(data, &r.bests); err != nil {
+		fmt.Println("error reading best collections:", err)
+		r.bests = make(bestCollections)
+	}
+	return r
+}
+
+// finish is called when the race is decided, a new best count for the level
+// is saved.
+func (r *collectionRecords) finish(levelID string, collected int) {
+	if !r.saveBests || collected == 0 {
+		return
+	}
+	if best, ok := r.bests[levelID]; ok && collected <= best {
+		return
+	}
+	r.bests[levelID] = collected
+	if err := r.save(); err != nil {
+		fmt.Println("error saving best collections:", err)
+	}
+}
+
+func (r *collectionRecords) save() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.bests, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, data, 0644)
+}
@@ -3,6 +3,7 @@ package main
 type CollisionObject struct {
 	Bounds    Rectangle
 	Solidness Solidness
+	Slope     Slope
 }
 
 type Solidness int
@@ -15,3 +16,102 @@ const (
 	// you, you can walk through it sideways and jump through it from below.
 	TopSolid
 )
+
+// Slope is the shape of an object's walkable top. Sloped objects are right
+// triangles in the lower part of their bounds, their top goes from one bottom
+// corner to the opposite top corner. A Solid slope stops you at its bottom and
+// at its high side, you walk onto it from its low side unless your feet are
+// below its bottom. A TopSolid slope can be walked and jumped through from all
+// sides but its top.
+type Slope int
+
+const (
+	// NoSlope means the whole rectangle is the object
+	NoSlope Slope = iota
+	// RisingRight goes up from the bottom-left to the top-right corner
+	RisingRight
+	// RisingLeft goes up from the bottom-right to the top-left corner
+	RisingLeft
+)
+
+// String returns the constant's name, the level editor writes these into the
+// level files.
+func (s Slope) String() string {
+	switch s {
+	case NoSlope:
+		return "NoSlope"
+	case RisingRight:
+		return "RisingRight"
+	case RisingLeft:
+		return "RisingLeft"
+	default:
+		return "unknown slope"
+	}
+}
+
+// SlopeStep is the largest distance that a character standing on a slope is
+// moved up or down in one update to keep her feet on the slope.
+const SlopeStep = 24
+
+// surfaceAt returns the y coordinate of a sloped object's top at column x,
+// which must be inside the object's bounds. Standing on the slope means the
+// character's bottom (Y+H) is at this y.
+func (o *CollisionObject) surfaceAt(x int) int {
+	b := o.Bounds
+	dx := x - b.X + 1 // from the left, 1 for the leftmost column
+	if o.Slope == RisingLeft {
+		dx = b.X + b.W - x
+	}
+	return b.Y + b.H - (dx*b.H+b.W/2)/b.W
+}
+
+// blocksX tells whether the object stops a character that moves sideways by dx
+// from bounds. Solid slopes stop you at their high side if it is too high to
+// step onto, this way two slopes can form a hill. On both sides they stop you
+// if your feet are below their bottom, that is you walk into the side of their
+// bottom edge.
+func (o *CollisionObject) blocksX(bounds Rectangle, dx int) bool {
+	if o.Solidness != Solid {
+		return false
+	}
+	bottom := o.Bounds.Y + o.Bounds.H
+	tooHigh := bounds.Y+bounds.H > o.Bounds.Y+SlopeStep
+	tooLow := bounds.Y+bounds.H > bottom && bounds.Y < bottom
+	left := bounds.X+bounds.W <= o.Bounds.X
+	right := bounds.X >= o.Bounds.X+o.Bounds.W
+	switch o.Slope {
+	case RisingRight:
+		return dx < 0 && right && (tooHigh || tooLow) || dx > 0 && left && tooLow
+	case RisingLeft:
+		return dx > 0 && left && (tooHigh || tooLow) || dx < 0 && right && tooLow
+	default:
+		return true
+	}
+}
+
+// cornerBeside returns the top pixel of the column of a Solid sloped object
+// that is closest to column x. ok is false if column x is part of the object
+// or if it is not Solid.
+func (o *CollisionObject) cornerBeside(x int) (corner Rectangle, ok bool) {
+	if o.Solidness != Solid {
+		return Rectangle{}, false
+	}
+	switch {
+	case x < o.Bounds.X:
+		x = o.Bounds.X
+	case x >= o.Bounds.X+o.Bounds.W:
+		x = o.Bounds.X + o.Bounds.W - 1
+	default:
+		return Rectangle{}, false
+	}
+	return Rectangle{x, o.surfaceAt(x), 1, 1}, true
+}
+
+// blocksUp tells whether the object stops a character that jumps up from
+// bounds. Solid slopes only stop you if you start below them.
+func (o *CollisionObject) blocksUp(bounds Rectangle) bool {
+	if o.Solidness != Solid {
+		return false
+	}
+	return o.Slope == NoSlope || bounds.Y >= o.Bounds.Y+o.Bounds.H
+}
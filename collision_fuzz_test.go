@@ -0,0 +1,235 @@
+// +build headless
+
+package main
+
+import (
+	"flag"
+	"math/rand"
+	"testing"
+)
+
+var collisionRuns = flag.Int("collision-runs", 20000,
+	"random layouts per seed in TestCollisionInvariants")
+
+// TestCollisionInvariants moves characters with random speeds through random
+// layouts of Solid and TopSolid objects, slopes and moving platforms, some of
+// the TopSolid objects are dropped through. It checks the invariants of the
+// swept collision:
+//
+//   - a character that does not overlap a Solid object never ends a move
+//     overlapping one, neither in MoveInX, MoveInY nor Character.Update; for
+//     slopes this means her bottom center does not end up below their surface,
+//     after MoveInX it may be up to SlopeStep below it since Update lifts her
+//   - falling never tunnels through the top of an object or a slope's surface,
+//     unless she drops through it
+//   - landing during Character.Update always sets InAir to false
+//   - a moving platform never carries a character into a static Solid object,
+//     it moves her with MoveInY and MoveInX so slopes allow SlopeStep
+//
+// The same seed always creates the same layouts, run more of them with e.g.
+// -collision-runs 1000000.
+func TestCollisionInvariants(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		checkCollisionInvariants(t, seed, *collisionRuns)
+	}
+}
+
+func checkCollisionInvariants(t *testing.T, seed int64, runs int) {
+	r := rand.New(rand.NewSource(seed))
+	failures := 0
+	fail := func(run int, format string, a ...interface{}) {
+		t.Helper()
+		t.Errorf("seed %v run %v: "+format, append([]interface{}{seed, run}, a...)...)
+		failures++
+		if failures >= 10 {
+			t.FailNow()
+		}
+	}
+
+	for run := 0; run < runs; run++ {
+		g := randomCollisionLayout(r)
+		static := g.objects[:len(g.objects)-len(g.platforms)]
+		char := &Character{
+			Position: Rectangle{r.Intn(400), r.Intn(400), 20 + r.Intn(30), 30 + r.Intn(40)},
+			Params:   HeroParams,
+			InAir:    r.Intn(2) == 0,
+			// Update animates the run frames so there has to be one
+			runFrames: [DirectionCount][]Image{{nil}, {nil}},
+		}
+		if overlapsSolid(g.objects, char.Position) {
+			continue
+		}
+		// she drops through some of the TopSolid objects
+		var collider Collider = g
+		var drop []int
+		if r.Intn(4) == 0 {
+			for i := range g.objects {
+				if g.objects[i].Solidness == TopSolid && g.objects[i].Slope == NoSlope && r.Intn(2) == 0 {
+					drop = append(drop, i)
+				}
+			}
+			collider = droppingCollider{g, drop}
+		}
+		// the speeds go a bit beyond the maximum speeds
+		char.SpeedX = r.Intn(2*HeroParams.MaxSpeedX+2*SubPixels+1) - HeroParams.MaxSpeedX - SubPixels
+		char.SpeedY = r.Intn(2*HeroParams.MaxSpeedY+1) - HeroParams.MaxSpeedY
+		start := char.Position
+		dx, dy := char.SpeedX/SubPixels, char.SpeedY/SubPixels
+
+		if pos, _ := collider.MoveInX(start, dx); solidAt(g.objects, pos, SlopeStep) != nil {
+			fail(run, "MoveInX(%v, %v) = %v overlaps %+v", start, dx, pos, *solidAt(g.objects, pos, SlopeStep))
+		}
+		pos, landed := collider.MoveInY(start, dy)
+		if solidAt(g.objects, pos, 0) != nil {
+			fail(run, "MoveInY(%v, %v) = %v overlaps %+v", start, dy, pos, *solidAt(g.objects, pos, 0))
+		}
+		if dy > 0 {
+			for i := range g.objects {
+				if !contains(drop, i) && tunneled(start, pos, &g.objects[i]) {
+					fail(run, "MoveInY(%v, %v) = %v falls through %+v",
+						start, dy, pos, g.objects[i])
+				}
+			}
+		}
+
+		char.Update(collider)
+		if solid := solidAt(g.objects, char.Position, 0); solid != nil {
+			fail(run, "Update from %v with speed %v,%v ends at %v overlapping %+v",
+				start, dx, dy, char.Position, *solid)
+		}
+		if dy > 0 && landed && char.InAir {
+			fail(run, "Update from %v with speed %v,%v lands at %v but is in the air",
+				start, dx, dy, char.Position)
+		}
+
+		// platforms move into characters that they do not carry and the ones
+		// they carry can be squashed against other objects, only the static
+		// objects are sure to stop a carried character
+		carried := char.Position
+		g.characters = [2]*Character{char, {Position: Rectangle{X: -1000, Y: -1000}, InAir: true}}
+		g.ghost = &ghostRunner{char: g.characters[1]}
+		g.frame++
+		g.movePlatforms()
+		if solid := solidAt(static, char.Position, SlopeStep); solid != nil {
+			fail(run, "a platform carries %v to %v overlapping %+v", carried, char.Position, *solid)
+		}
+	}
+}
+
+// randomCollisionLayout creates a Game with only collision objects and moving
+// platforms in it, at a random frame.
+func randomCollisionLayout(r *rand.Rand) *Game {
+	g := &Game{}
+	n := 1 + r.Intn(12)
+	for i := 0; i < n; i++ {
+		obj := CollisionObject{
+			Bounds:    Rectangle{r.Intn(400), r.Intn(400), 1 + r.Intn(120), 1 + r.Intn(40)},
+			Solidness: TopSolid,
+		}
+		if r.Intn(2) == 0 {
+			obj.Solidness = Solid
+		}
+		// slopes are at most as steep as 45 degrees, on steeper ones the
+		// characters can not follow the surface
+		if r.Intn(3) == 0 {
+			obj.Slope = RisingRight + Slope(r.Intn(2))
+			obj.Bounds.H = 1 + r.Intn(obj.Bounds.W)
+		}
+		g.objects = append(g.objects, obj)
+	}
+	g.grid = newCollisionGrid(g.objects, CollisionGridCellSize)
+
+	for i := r.Intn(3); i > 0; i-- {
+		p := LevelPlatform{
+			W:     1 + r.Intn(120),
+			H:     1 + r.Intn(40),
+			Solid: r.Intn(2) == 0,
+			Speed: r.Intn(8),
+			Mode:  PathMode(r.Intn(2)),
+		}
+		for j := 1 + r.Intn(3); j > 0; j-- {
+			p.Path = append(p.Path, Point{r.Intn(400), r.Intn(400)})
+		}
+		obj := CollisionObject{Bounds: Rectangle{W: p.W, H: p.H}, Solidness: TopSolid}
+		if p.Solid {
+			obj.Solidness = Solid
+		}
+		g.platforms = append(g.platforms, newMovingPlatform(len(g.objects), &p))
+		g.objects = append(g.objects, obj)
+	}
+	g.frame = r.Intn(1000)
+	g.placePlatforms()
+	return g
+}
+
+// solidAt returns one of the Solid objects that r overlaps, or nil. Characters stand on
+// slopes with their bottom center, r only overlaps a slope if that is more
+// than slopeDepth pixels below its surface.
+func solidAt(objects []CollisionObject, r Rectangle, slopeDepth int) *CollisionObject {
+	for i := range objects {
+		o := &objects[i]
+		if o.Solidness != Solid {
+			continue
+		}
+		if o.Slope == NoSlope && o.Bounds.Overlaps(r) ||
+			o.Slope != NoSlope && belowSurface(o, r) > slopeDepth {
+			return o
+		}
+	}
+	return nil
+}
+
+// overlapsSolid tells if any part of r overlaps a Solid object, for slopes only
+// the triangle below their surface counts.
+func overlapsSolid(objects []CollisionObject, r Rectangle) bool {
+	for i := range objects {
+		o := &objects[i]
+		if o.Solidness != Solid || !o.Bounds.Overlaps(r) {
+			continue
+		}
+		if o.Slope == NoSlope {
+			return true
+		}
+		// the surface is highest in the column closest to the high side
+		x := min(r.X+r.W, o.Bounds.X+o.Bounds.W) - 1
+		if o.Slope == RisingLeft {
+			x = max(r.X, o.Bounds.X)
+		}
+		if r.Y+r.H > o.surfaceAt(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// belowSurface returns how far r's bottom center is below the surface of the
+// sloped object o, it is 0 if r is not inside o. If r is only partly above o,
+// the surface of the column closest to its center counts, r may be up to
+// SlopeStep below it since that is how high characters step onto slopes.
+func belowSurface(o *CollisionObject, r Rectangle) int {
+	if !o.Bounds.Overlaps(r) {
+		return 0
+	}
+	x := r.X + r.W/2
+	if x < o.Bounds.X || x >= o.Bounds.X+o.Bounds.W {
+		x = max(o.Bounds.X, min(x, o.Bounds.X+o.Bounds.W-1))
+		return max(0, r.Y+r.H-o.surfaceAt(x)-SlopeStep)
+	}
+	return max(0, r.Y+r.H-o.surfaceAt(x))
+}
+
+// tunneled tells if a character that fell from start to end went past the top
+// of an object below it instead of stopping on it.
+func tunneled(start, end Rectangle, obj *CollisionObject) bool {
+	top := obj.Bounds.Y
+	if obj.Slope != NoSlope {
+		x := start.X + start.W/2
+		if x < obj.Bounds.X || x >= obj.Bounds.X+obj.Bounds.W {
+			return false
+		}
+		top = obj.surfaceAt(x)
+	} else if obj.Bounds.X >= start.X+start.W || obj.Bounds.X+obj.Bounds.W <= start.X {
+		return false
+	}
+	return start.Y+start.H <= top && top < end.Y+end.H
+}
@@ -0,0 +1,43 @@
+package main
+
+// DropThroughFrames is how long a character falls through the TopSolid objects
+// that she stood on when pressing down.
+const DropThroughFrames = 15
+
+// dropThroughObjects returns the indices of the TopSolid objects that bounds
+// stands on. ok is false if there are none or if bounds also stands on a Solid
+// object, you cannot drop through those. Slopes are not dropped through.
+func (g *Game) dropThroughObjects(bounds Rectangle) (objects []int, ok bool) {
+	feet := Rectangle{bounds.X, bounds.Y + bounds.H, bounds.W, 1}
+	for _, i := range g.nearObjects(feet) {
+		obj := &g.objects[i]
+		if obj.Slope != NoSlope || obj.Bounds.Y != feet.Y || !obj.Bounds.Overlaps(feet) {
+			continue
+		}
+		if obj.Solidness == Solid {
+			return nil, false
+		}
+		objects = append(objects, i)
+	}
+	return objects, len(objects) > 0
+}
+
+// droppingCollider is the Game's collision but falling through the objects that
+// a character drops through.
+type droppingCollider struct {
+	*Game
+	dropThrough []int
+}
+
+func (c droppingCollider) MoveInY(bounds Rectangle, dy int) (Rectangle, bool) {
+	return c.moveInY(bounds, dy, c.dropThrough)
+}
+
+func contains(list []int, x int) bool {
+	for _, y := range list {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
@@ -1,35 +1,42 @@
 package main
 
 var dxTexturePso = []byte{
-	0x00, 0x02, 0xff, 0xff, 0xfe, 0xff, 0x24, 0x00,
+	0x00, 0x02, 0xff, 0xff, 0xfe, 0xff, 0x2f, 0x00,
 	0x43, 0x54, 0x41, 0x42, 0x1c, 0x00, 0x00, 0x00,
-	0x57, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xff,
-	0x01, 0x00, 0x00, 0x00, 0x1c, 0x00, 0x00, 0x00,
-	0x00, 0x01, 0x04, 0x00, 0x50, 0x00, 0x00, 0x00,
-	0x30, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00,
-	0x01, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x74, 0x65, 0x78, 0x74,
-	0x75, 0x72, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c,
-	0x65, 0x72, 0x00, 0xab, 0x04, 0x00, 0x0c, 0x00,
-	0x01, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x70, 0x73, 0x5f, 0x32,
-	0x5f, 0x30, 0x00, 0x4d, 0x69, 0x63, 0x72, 0x6f,
-	0x73, 0x6f, 0x66, 0x74, 0x20, 0x28, 0x52, 0x29,
-	0x20, 0x48, 0x4c, 0x53, 0x4c, 0x20, 0x53, 0x68,
-	0x61, 0x64, 0x65, 0x72, 0x20, 0x43, 0x6f, 0x6d,
-	0x70, 0x69, 0x6c, 0x65, 0x72, 0x20, 0x36, 0x2e,
-	0x33, 0x2e, 0x39, 0x36, 0x30, 0x30, 0x2e, 0x31,
-	0x36, 0x33, 0x38, 0x34, 0x00, 0xab, 0xab, 0xab,
-	0x1f, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x80,
-	0x00, 0x00, 0x03, 0xb0, 0x1f, 0x00, 0x00, 0x02,
-	0x00, 0x00, 0x00, 0x90, 0x00, 0x08, 0x0f, 0xa0,
-	0x42, 0x00, 0x00, 0x03, 0x00, 0x00, 0x0f, 0x80,
-	0x00, 0x00, 0xe4, 0xb0, 0x00, 0x08, 0xe4, 0xa0,
-	0x01, 0x00, 0x00, 0x02, 0x01, 0x00, 0x09, 0x80,
-	0x00, 0x00, 0xd2, 0x80, 0x01, 0x00, 0x00, 0x02,
-	0x01, 0x00, 0x04, 0x80, 0x00, 0x00, 0x00, 0x80,
-	0x01, 0x00, 0x00, 0x02, 0x01, 0x00, 0x02, 0x80,
-	0x00, 0x00, 0x55, 0x80, 0x01, 0x00, 0x00, 0x02,
-	0x00, 0x08, 0x0f, 0x80, 0x01, 0x00, 0xe4, 0x80,
-	0xff, 0xff, 0x00, 0x00,
+	0x83, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xff,
+	0x02, 0x00, 0x00, 0x00, 0x1c, 0x00, 0x00, 0x00,
+	0x00, 0x01, 0x04, 0x00, 0x7c, 0x00, 0x00, 0x00,
+	0x44, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x00, 0x00, 0x4c, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x5c, 0x00, 0x00, 0x00,
+	0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+	0x6c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x6f, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x00,
+	0x00, 0x00, 0x03, 0x00, 0x01, 0x00, 0x01, 0x00,
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x74, 0x65, 0x78, 0x74, 0x75, 0x72, 0x65, 0x53,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x00, 0xab,
+	0x04, 0x00, 0x0c, 0x00, 0x01, 0x00, 0x01, 0x00,
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x70, 0x73, 0x5f, 0x32, 0x5f, 0x30, 0x00, 0x4d,
+	0x69, 0x63, 0x72, 0x6f, 0x73, 0x6f, 0x66, 0x74,
+	0x20, 0x28, 0x52, 0x29, 0x20, 0x48, 0x4c, 0x53,
+	0x4c, 0x20, 0x53, 0x68, 0x61, 0x64, 0x65, 0x72,
+	0x20, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65,
+	0x72, 0x20, 0x36, 0x2e, 0x33, 0x2e, 0x39, 0x36,
+	0x30, 0x30, 0x2e, 0x31, 0x36, 0x33, 0x38, 0x34,
+	0x00, 0xab, 0xab, 0xab, 0x1f, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x03, 0xb0,
+	0x1f, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x90,
+	0x00, 0x08, 0x0f, 0xa0, 0x42, 0x00, 0x00, 0x03,
+	0x00, 0x00, 0x0f, 0x80, 0x00, 0x00, 0xe4, 0xb0,
+	0x00, 0x08, 0xe4, 0xa0, 0x05, 0x00, 0x00, 0x03,
+	0x01, 0x00, 0x08, 0x80, 0x00, 0x00, 0xff, 0x80,
+	0x00, 0x00, 0x00, 0xa0, 0x01, 0x00, 0x00, 0x02,
+	0x01, 0x00, 0x01, 0x80, 0x00, 0x00, 0xd2, 0x80,
+	0x01, 0x00, 0x00, 0x02, 0x01, 0x00, 0x04, 0x80,
+	0x00, 0x00, 0x00, 0x80, 0x01, 0x00, 0x00, 0x02,
+	0x01, 0x00, 0x02, 0x80, 0x00, 0x00, 0x55, 0x80,
+	0x01, 0x00, 0x00, 0x02, 0x00, 0x08, 0x0f, 0x80,
+	0x01, 0x00, 0xe4, 0x80, 0xff, 0xff, 0x00, 0x00,
 }
@@ -0,0 +1,57 @@
+package main
+
+import "time"
+
+const (
+	// UpdatesPerSecond is the fixed rate of the game simulation, every call to
+	// Game.Update advances the game by 1/UpdatesPerSecond seconds
+	UpdatesPerSecond = 65
+	// MaxCatchUpUpdates limits the number of updates per rendered frame; if the
+	// game falls further behind (e.g. the window was dragged or the machine is
+	// too slow) the remaining time is dropped instead of simulating it all
+	MaxCatchUpUpdates = 10
+)
+
+// fixedStepLoop accumulates real time and converts it into a whole number of
+// fixed-size simulation steps. This way the game runs at the same speed and
+// produces the same results independent of the rendering frame rate, which is
+// what keeps recorded inputs in sync with the simulation.
+type fixedStepLoop struct {
+	step     time.Duration
+	maxSteps int
+	last     time.Time
+	lag      time.Duration
+}
+
+func newFixedStepLoop(stepsPerSecond, maxSteps int) *fixedStepLoop {
+	return &fixedStepLoop{
+		step:     time.Second / time.Duration(stepsPerSecond),
+		maxSteps: maxSteps,
+	}
+}
+
+// advance calls update once for every full step that passed since the last
+// call and returns the fraction of a step that is left over, in [0,1). This
+// alpha is used to interpolate between the last two simulated states when
+// rendering.
+func (l *fixedStepLoop) advance(now time.Time, update func()) (alpha float64) {
+	if l.last.IsZero() {
+		// update right away in the very first frame
+		l.last = now
+		l.lag = l.step
+	}
+	l.lag += now.Sub(l.last)
+	l.last = now
+
+	for steps := 0; l.lag >= l.step; steps++ {
+		if steps >= l.maxSteps {
+			// drop the time we can not catch up on but keep the fraction
+			l.lag %= l.step
+			break
+		}
+		update()
+		l.lag -= l.step
+	}
+
+	return float64(l.lag) / float64(l.step)
+}
@@ -0,0 +1,89 @@
+package main
+
+import "strings"
+
+// the HUD font is a tiny bitmap font, every glyph is 3x5 pixels where a '#'
+// marks a filled pixel; lower case letters are drawn as upper case
+var glyphs = map[rune][5]string{
+	'0':  {"###", "# #", "# #", "# #", "###"},
+	'1':  {" # ", "## ", " # ", " # ", "###"},
+	'2':  {"###", "  #", "###", "#  ", "###"},
+	'3':  {"###", "  #", " ##", "  #", "###"},
+	'4':  {"# #", "# #", "###", "  #", "  #"},
+	'5':  {"###", "#  ", "###", "  #", "###"},
+	'6':  {"###", "#  ", "###", "# #", "###"},
+	'7':  {"###", "  #", " # ", " # ", " # "},
+	'8':  {"###", "# #", "###", "# #", "###"},
+	'9':  {"###", "# #", "###", "  #", "###"},
+	'A':  {" # ", "# #", "###", "# #", "# #"},
+	'B':  {"## ", "# #", "## ", "# #", "## "},
+	'C':  {" ##", "#  ", "#  ", "#  ", " ##"},
+	'D':  {"## ", "# #", "# #", "# #", "## "},
+	'E':  {"###", "#  ", "## ", "#  ", "###"},
+	'F':  {"###", "#  ", "## ", "#  ", "#  "},
+	'G':  {" ##", "#  ", "# #", "# #", " ##"},
+	'H':  {"# #", "# #", "###", "# #", "# #"},
+	'I':  {"###", " # ", " # ", " # ", "###"},
+	'J':  {"  #", "  #", "  #", "# #", " # "},
+	'K':  {"# #", "# #", "## ", "# #", "# #"},
+	'L':  {"#  ", "#  ", "#  ", "#  ", "###"},
+	'M':  {"# #", "###", "###", "# #", "# #"},
+	'N':  {"## ", "# #", "# #", "# #", "# #"},
+	'O':  {" # ", "# #", "# #", "# #", " # "},
+	'P':  {"## ", "# #", "## ", "#  ", "#  "},
+	'Q':  {" # ", "# #", "# #", "## ", " ##"},
+	'R':  {"## ", "# #", "## ", "# #", "# #"},
+	'S':  {" ##", "#  ", " # ", "  #", "## "},
+	'T':  {"###", " # ", " # ", " # ", " # "},
+	'U':  {"# #", "# #", "# #", "# #", "###"},
+	'V':  {"# #", "# #", "# #", "# #", " # "},
+	'W':  {"# #", "# #", "###", "###", "# #"},
+	'X':  {"# #", "# #", " # ", "# #", "# #"},
+	'Y':  {"# #", "# #", " # ", " # ", " # "},
+	'Z':  {"###", "  #", " # ", "#  ", "###"},
+	':':  {"   ", " # ", "   ", " # ", "   "},
+	'.':  {"   ", "   ", "   ", "   ", " # "},
+	'+':  {"   ", " # ", "###", " # ", "   "},
+	'-':  {"   ", "   ", "###", "   ", "   "},
+	'/':  {"  #", "  #", " # ", "#  ", "#  "},
+	'!':  {" # ", " # ", " # ", "   ", " # "},
+	'?':  {"###", "  #", " # ", "   ", " # "},
+	',':  {"   ", "   ", "   ", " # ", "#  "},
+	'\'': {" # ", " # ", "   ", "   ", "   "},
+}
+
+const (
+	glyphW = 3
+	glyphH = 5
+)
+
+// drawText draws the text in screen coordinates with its top-left corner at
+// x,y. Every glyph pixel is drawn as a square of pixelSize. Unknown characters
+// are drawn as spaces.
+func drawText(graphics Graphics, text string, x, y, pixelSize int, r, g, b uint8) {
+	for _, char := range strings.ToUpper(text) {
+		glyph := glyphs[char]
+		for row := range glyph {
+			for col, pixel := range glyph[row] {
+				if pixel == '#' {
+					graphics.FillRect(Rectangle{
+						x + col*pixelSize,
+						y + row*pixelSize,
+						pixelSize,
+						pixelSize,
+					}, r, g, b)
+				}
+			}
+		}
+		x += (glyphW + 1) * pixelSize
+	}
+}
+
+// textSize returns the size of the text drawn with drawText.
+func textSize(text string, pixelSize int) (width, height int) {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0, 0
+	}
+	return (n*(glyphW+1) - 1) * pixelSize, glyphH * pixelSize
+}
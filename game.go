@@ -1,5 +1,10 @@
 package main
 
+import (
+	"fmt"
+	"time"
+)
+
 const (
 	PrePlayFrameDelay    = 100
 	PlayerDyingDelay     = 100
@@ -10,6 +15,10 @@ const (
 	IntroDuration        = 930
 )
 
+// startPositions are the bottom-center points where the hero and Barney start
+// the race.
+var startPositions = [2]Point{{500, 537}, {300, 537}}
+
 type Game struct {
 	graphics Graphics
 	camera   Camera
@@ -18,24 +27,73 @@ type Game struct {
 	prePlayCountDown     int
 	playerDyingCountDown int
 	dieBounds            Rectangle
-	aiInputs             []inputRecord
-	goalBounds           Rectangle
 	losingSoundCountDown int
 	barneyWinCountDown   int
 	playerWinCountDown   int
 	introCountUp         int
+	// frame counts the simulated frames since the race started
+	frame int
+
+	// the triggers can change the camera bounds, set the hero's checkpoint
+	// and show hints; levelCameraBounds are the bounds at the start
+	triggers          []trigger
+	levelCameraBounds Rectangle
+	cameraBounds      Rectangle
+	hint              string
+	hintCountDown     int
+
+	// checkpoint is where the hero respawns in levels with the respawn mode
+	// RespawnAtCheckpoint, touching one of the checkpoints moves it
+	respawnMode RespawnMode
+	checkpoints []Rectangle
+	checkpoint  Point
+
+	// characterCollision has the hero and Barney bump into each other
+	characterCollision bool
+
+	// recorder records the inputs of the user controlled characters, aiPlayer
+	// plays back Barney's recorded inputs; they start at aiStartFrame, which
+	// is the frame that Barney last respawned in
+	recorder     *InputRecorder
+	aiPlayer     *InputPlayer
+	aiStartFrame int
+	// replayPlayer is set when watching a replay, the user controls are
+	// disabled then
+	replayPlayer *InputPlayer
+	// ghost races the hero's best run of this session
+	ghost *ghostRunner
+	timer *speedrunTimer
+
+	// while rewinding, the game steps backwards through the snapshots in the
+	// rewind buffer instead of simulating; heldKeys is the input state of the
+	// keys that the user is holding, it is applied when the rewind is over
+	rewind    *rewindBuffer
+	rewinding bool
+	heldKeys  inputState
 
 	running          bool
 	characters       [2]*Character
 	inputStates      [2]inputState
 	primaryCharIndex int
+	// cameraTarget is the index of the character that the camera follows
+	cameraTarget int
 
+	levelID      string
 	objects      []CollisionObject
+	grid         *collisionGrid
+	platforms    []movingPlatform
+	hazards      []hazardObject
+	zones        []LevelZone
+	bouncePads   []bouncePad
+	collectibles []collectible
+	// collections keeps the best number of collected items per level
+	collections  *collectionRecords
 	imageObjects []ImageObject
 
 	winningSound         Sound
 	losingSound          Sound
 	fallingSound         Sound
+	pickupSound          Sound
 	barneyWinSound       Sound
 	whistleSound         Sound
 	barneyIntroTextSound Sound
@@ -58,6 +116,7 @@ type inputState struct {
 	rightDown         bool
 	jumpDown          bool
 	mustJumpThisFrame bool
+	downDown          bool
 }
 
 type GameState int
@@ -77,13 +136,14 @@ func NewGame(
 	graphics Graphics,
 	cam Camera,
 	cameraFocusCharIndex int,
+	levelID string,
 ) *Game {
 	hero := NewHero(assets)
-	hero.SetBottomCenterTo(500, 537)
+	hero.SetBottomCenterTo(startPositions[0].X, startPositions[0].Y)
 	hero.Direction = RightDirectionIndex
 
 	barney := NewBarney(assets)
-	barney.SetBottomCenterTo(300, 537)
+	barney.SetBottomCenterTo(startPositions[1].X, startPositions[1].Y)
 	barney.Direction = RightDirectionIndex
 
 	cameraBounds := Rectangle{200, -1399, 9150, 2100}
@@ -94,13 +154,20 @@ func NewGame(
 		graphics:             graphics,
 		characters:           [2]*Character{hero, barney},
 		primaryCharIndex:     cameraFocusCharIndex,
+		cameraTarget:         cameraFocusCharIndex,
 		camera:               cam,
 		dieBounds:            cameraBounds.AddMargin(200),
-		aiInputs:             recordedInputs,
-		goalBounds:           Rectangle{9200, -1000, 1000, 350},
+		levelCameraBounds:    cameraBounds,
+		cameraBounds:         cameraBounds,
+		checkpoint:           startPositions[0],
+		recorder:             NewInputRecorder(),
+		aiPlayer:             NewInputPlayer(recordedInputs),
+		ghost:                newGhostRunner(assets),
+		rewind:               newRewindBuffer(RewindFrames),
 		winningSound:         assets.LoadSound("win"),
 		losingSound:          assets.LoadSound("lose"),
 		fallingSound:         assets.LoadSound("fall"),
+		pickupSound:          assets.LoadSound("pickup"),
 		barneyWinSound:       assets.LoadSound("barney wins"),
 		whistleSound:         assets.LoadSound("whistle"),
 		barneyIntroTextSound: assets.LoadSound("barney intro text"),
@@ -109,12 +176,28 @@ func NewGame(
 		introPC2:             assets.LoadImage("intro pc 2"),
 		introGophette:        assets.LoadImage("intro gophette"),
 	}
-	game.loadLevel(assets, &level1)
-	game.state = IntroPCScene
+	game.loadLevel(assets, levelID)
+	game.timer = newSpeedrunTimer(levelID, personalBestsFile())
+	game.collections = newCollectionRecords(bestCollectionsFile())
+	// only the hero's runs count as personal bests
+	game.timer.saveBests = cameraFocusCharIndex == 0
+	game.collections.saveBests = cameraFocusCharIndex == 0
+	game.startIntro()
 	return game
 }
 
-func (g *Game) loadLevel(assets AssetLoader, level *Level) {
+// startIntro plays the intro cut-scene, after it the race starts or goes on.
+func (g *Game) startIntro() {
+	g.state = IntroPCScene
+	g.introCountUp = 0
+	g.currentIntroPCImage = 0
+	g.introBarneyTalking = false
+}
+
+func (g *Game) loadLevel(assets AssetLoader, id string) {
+	level := Levels[id]
+	g.levelID = id
+
 	g.imageObjects = make([]ImageObject, len(level.Images))
 	for i := range level.Images {
 		img := &level.Images[i]
@@ -123,46 +206,205 @@ func (g *Game) loadLevel(assets AssetLoader, level *Level) {
 		g.imageObjects[i].Y = img.Y
 	}
 
-	g.objects = make([]CollisionObject, len(level.Objects))
+	g.objects = nil
+	g.hazards = nil
 	for i := range level.Objects {
-		g.objects[i].Solidness = TopSolid
-		if level.Objects[i].Solid {
-			g.objects[i].Solidness = Solid
+		o := &level.Objects[i]
+		bounds := Rectangle{o.X, o.Y, o.W, o.H}
+		// hazards do not collide, they are only checked for touching
+		if o.Hazard != NoHazard {
+			h := hazardObject{Bounds: bounds, Kind: o.Hazard}
+			if o.Image != "" {
+				h.image = assets.LoadImage(o.Image)
+			}
+			g.hazards = append(g.hazards, h)
+			continue
+		}
+		obj := CollisionObject{Bounds: bounds, Solidness: TopSolid, Slope: o.Slope}
+		if o.Solid {
+			obj.Solidness = Solid
+		}
+		g.objects = append(g.objects, obj)
+	}
+	// characters land on bounce pads like on TopSolid objects
+	for _, p := range level.BouncePads {
+		g.objects = append(g.objects, CollisionObject{
+			Bounds:    Rectangle{p.X, p.Y, p.W, p.H},
+			Solidness: TopSolid,
+		})
+	}
+	g.loadBouncePads(assets, level)
+	g.loadCollectibles(assets, level)
+	// the grid only contains the static objects, the moving platforms are
+	// appended after them
+	g.grid = newCollisionGrid(g.objects, CollisionGridCellSize)
+
+	g.loadTriggers(assets, level)
+	g.loadCheckpoints(level)
+	g.characterCollision = level.CharacterCollision
+	g.zones = level.Zones
+
+	g.platforms = make([]movingPlatform, len(level.Platforms))
+	for i := range level.Platforms {
+		p := &level.Platforms[i]
+		obj := CollisionObject{Bounds: Rectangle{W: p.W, H: p.H}, Solidness: TopSolid}
+		if p.Solid {
+			obj.Solidness = Solid
 		}
-		g.objects[i].Bounds = Rectangle{
-			level.Objects[i].X,
-			level.Objects[i].Y,
-			level.Objects[i].W,
-			level.Objects[i].H,
+		g.platforms[i] = newMovingPlatform(len(g.objects), p)
+		if p.Image.ID != "" {
+			g.platforms[i].image = assets.LoadImage(p.Image.ID)
 		}
+		g.objects = append(g.objects, obj)
 	}
+	g.placePlatforms()
 }
 
-func (g *Game) HandleInput(event InputEvent) {
-	recordInput(event)
+// SetAIInputs replaces Barney's recorded inputs, pass nil to have Barney stand
+// still, e.g. when recording a new run for him.
+func (g *Game) SetAIInputs(records []inputRecord) {
+	g.aiPlayer = NewInputPlayer(records)
+}
 
-	inputState := &g.inputStates[event.CharacterIndex]
+// SetAIReplay has Barney play back the given run. The replay's first
+// character's params are used for Barney so the run plays back as recorded.
+func (g *Game) SetAIReplay(replay *Replay) {
+	g.SetAIInputs(replay.Inputs)
+	if len(replay.Characters) > 0 {
+		g.characters[1].Params = replay.Characters[0].Params
+	}
+}
 
-	if event.Action == GoLeft {
-		inputState.leftDown = event.Pressed
+// WatchReplay plays back the inputs of all characters in the replay, the user
+// can only quit the game while watching. The camera follows the first
+// character in the replay.
+func (g *Game) WatchReplay(replay *Replay) {
+	g.replayPlayer = NewInputPlayer(replay.Inputs)
+	g.ghost.disable()
+	g.timer.saveBests = false
+	g.collections.saveBests = false
+	for i, char := range replay.Characters {
+		g.characters[char.Index].Params = char.Params
+		if char.Index == 1 {
+			// Barney's inputs come from the replay, not from his AI
+			g.SetAIInputs(nil)
+		}
+		if i == 0 {
+			g.primaryCharIndex = char.Index
+			g.cameraTarget = char.Index
+		}
 	}
-	if event.Action == GoRight {
-		inputState.rightDown = event.Pressed
+}
+
+func (g *Game) Recorder() *InputRecorder {
+	return g.recorder
+}
+
+// Replay creates a replay from what the recorder recorded so far.
+func (g *Game) Replay() *Replay {
+	replay := &Replay{
+		Version:      ReplayVersion,
+		LevelID:      g.levelID,
+		Date:         time.Now(),
+		Result:       Unfinished,
+		FrameCount:   g.recorder.Frame(),
+		Inputs:       g.recorder.Records(),
+		HashInterval: g.recorder.HashInterval(),
+		Checks:       g.recorder.Checks(),
 	}
-	if event.Action == Jump {
-		inputState.mustJumpThisFrame = event.Pressed
-		inputState.jumpDown = event.Pressed
+	if g.state == PlayerWinning {
+		replay.Result = HeroWon
 	}
+	if g.state == PlayerRealizingLoss || g.state == CameraShowsBarneyWinning {
+		replay.Result = BarneyWon
+	}
+	for _, index := range g.recorder.Characters() {
+		replay.Characters = append(replay.Characters, ReplayCharacter{
+			Index:  index,
+			Params: g.characters[index].Params,
+		})
+	}
+	return replay
+}
+
+// HandleInput handles user input, it is ignored while watching a replay.
+func (g *Game) HandleInput(event InputEvent) {
+	if event.Action == Rewind {
+		if event.Pressed {
+			g.startRewinding()
+		} else if g.rewinding {
+			g.stopRewinding()
+		}
+		return
+	}
+
+	if event.CharacterIndex == g.primaryCharIndex {
+		g.heldKeys.apply(event)
+	}
+	if g.replayPlayer != nil && event.Action != QuitGame {
+		return
+	}
+	if g.rewinding && event.Action != QuitGame {
+		// the held keys are applied when the rewind is over
+		return
+	}
+	g.handleInput(event)
+}
+
+func (g *Game) handleInput(event InputEvent) {
+	g.recorder.Record(event)
+	if event.CharacterIndex == 0 {
+		g.ghost.record(event)
+	}
+
+	g.inputStates[event.CharacterIndex].apply(event)
 
 	if event.Action == QuitGame {
 		g.running = false
-		if recordingInput {
-			saveRecordedInputs()
+		if g.recorder.Recording() {
+			err := SaveReplayFile(recordedReplayFile, g.Replay())
+			if err != nil {
+				fmt.Println("error saving replay:", err)
+			}
 		}
 	}
 }
 
+func (s *inputState) apply(event InputEvent) {
+	if event.Action == GoLeft {
+		s.leftDown = event.Pressed
+	}
+	if event.Action == GoRight {
+		s.rightDown = event.Pressed
+	}
+	if event.Action == Jump {
+		s.mustJumpThisFrame = event.Pressed
+		s.jumpDown = event.Pressed
+	}
+	if event.Action == GoDown {
+		s.downDown = event.Pressed
+	}
+}
+
 func (g *Game) Update() {
+	if g.rewinding && g.state != IntroPCScene {
+		g.rewindStep()
+		return
+	}
+
+	g.update()
+
+	if g.state != IntroPCScene {
+		g.rewind.push(g)
+	}
+}
+
+func (g *Game) update() {
+	for _, char := range g.characters {
+		char.lastPosition = char.Position
+	}
+	g.ghost.char.lastPosition = g.ghost.char.Position
+
 	if g.state == IntroPCScene {
 		g.introCountUp++
 
@@ -188,38 +430,52 @@ func (g *Game) Update() {
 			g.state = PrePlaying
 		}
 	} else if g.state == Playing {
-		for len(g.aiInputs) > 0 && g.aiInputs[0].frame == frame {
-			if g.aiInputs[0].event.Action != QuitGame {
-				g.aiInputs[0].event.CharacterIndex = 1
-				g.HandleInput(g.aiInputs[0].event)
+		g.aiPlayer.Play(g.frame-g.aiStartFrame, func(event InputEvent) {
+			if event.Action != QuitGame {
+				event.CharacterIndex = 1
+				g.handleInput(event)
 			}
-			g.aiInputs = g.aiInputs[1:]
+		})
+		if g.replayPlayer != nil {
+			g.replayPlayer.Play(g.frame, func(event InputEvent) {
+				if event.Action != QuitGame {
+					g.handleInput(event)
+				}
+			})
 		}
 
-		frame++
+		g.ghost.play(g.frame)
 
+		g.frame++
+		g.recorder.NextFrame()
+		g.ghost.nextFrame()
+
+		g.movePlatforms()
 		g.updateCharacter(0)
 		g.updateCharacter(1)
+		if g.characterCollision {
+			g.collideCharacters()
+		}
+		g.ghost.update(g)
+		g.timer.update(g.frame, g.characters[0].Position)
 
-		if !g.dieBounds.Overlaps(g.characters[0].Position) {
-			g.fallingSound.PlayOnce()
-			g.state = PlayerDying
-			g.playerDyingCountDown = PlayerDyingDelay
+		if !g.dieBounds.Overlaps(g.characters[0].Position) ||
+			g.hazardAt(g.characters[0].Position) != NoHazard {
+			g.killHero()
+		}
+		if g.hazardAt(g.characters[1].Position) != NoHazard {
+			g.respawnBarney()
 		}
 
-		if g.goalBounds.Contains(g.characters[0].Position) {
-			g.winningSound.PlayOnce()
-			g.playerWinCountDown = PlayerWinDelay
-			g.state = PlayerWinning
-		} else if g.goalBounds.Contains(g.characters[1].Position) {
-			g.losingSound.PlayOnce()
-			g.state = PlayerRealizingLoss
-			g.losingSoundCountDown = LosingSoundDelay
+		if g.hintCountDown > 0 {
+			g.hintCountDown--
 		}
+		g.updateCheckpoints()
+		g.updateCollectibles()
+		g.updateTriggers()
 
-		g.camera.CenterAround(g.characters[g.primaryCharIndex].Position.Center())
+		g.recorder.Check(g.simState())
 	} else if g.state == PrePlaying {
-		g.camera.CenterAround(g.characters[g.primaryCharIndex].Position.Center())
 		g.prePlayCountDown--
 		if g.prePlayCountDown == WhistleSoundDuration {
 			g.whistleSound.PlayOnce()
@@ -237,7 +493,8 @@ func (g *Game) Update() {
 		g.characters[1].Reset(RightDirectionIndex)
 		g.playerWinCountDown--
 		if g.playerWinCountDown < 0 {
-			// TODO go to end cut-scene
+			// TODO go to end cut-scene, until there is one, race again
+			g.resetLevel()
 		}
 	} else if g.state == PlayerRealizingLoss {
 		g.losingSoundCountDown--
@@ -246,9 +503,9 @@ func (g *Game) Update() {
 			g.barneyWinCountDown = BarneyWinDelay
 			g.barneyWinSound.PlayOnce()
 			g.characters[1].Reset(LeftDirectionIndex)
+			g.cameraTarget = 1
 		}
 	} else if g.state == CameraShowsBarneyWinning {
-		g.camera.CenterAround(g.characters[1].Position.Center())
 		g.barneyWinCountDown--
 		if g.barneyWinCountDown <= 0 {
 			g.resetLevel()
@@ -257,25 +514,44 @@ func (g *Game) Update() {
 }
 
 func (g *Game) resetLevel() {
-	g.characters[0].SetBottomCenterTo(500, 537)
+	g.checkpoint = startPositions[0]
+	g.characters[0].SetBottomCenterTo(g.checkpoint.X, g.checkpoint.Y)
 	g.characters[0].Reset(RightDirectionIndex)
 
-	g.characters[1].SetBottomCenterTo(300, 537)
+	g.characters[1].SetBottomCenterTo(startPositions[1].X, startPositions[1].Y)
 	g.characters[1].Reset(RightDirectionIndex)
 
-	g.aiInputs = make([]inputRecord, len(recordedInputs))
-	copy(g.aiInputs, recordedInputs)
-	frame = 0
+	// the hero's keys might be held down from the last attempt
+	g.ghost.startAttempt(g.inputStates[0], g.checkpoint)
+
+	g.aiPlayer.Rewind()
+	g.aiStartFrame = 0
+	if g.replayPlayer != nil {
+		g.replayPlayer.Rewind()
+	}
+	g.frame = 0
+	g.placePlatforms()
+	g.resetTriggers()
+	g.resetCollectibles()
+	g.setCameraBounds(g.levelCameraBounds)
+	g.hintCountDown = 0
+	g.recorder.Restart()
+	g.timer.reset()
 
 	g.inputStates[1] = inputState{}
 
 	g.state = PrePlaying
 	g.prePlayCountDown = PrePlayFrameDelay
+	g.cameraTarget = g.primaryCharIndex
 }
 
 func (g *Game) updateCharacter(charIndex int) {
-	char := g.characters[charIndex]
-	inputState := &g.inputStates[charIndex]
+	g.moveCharacter(g.characters[charIndex], &g.inputStates[charIndex])
+}
+
+func (g *Game) moveCharacter(char *Character, inputState *inputState) {
+	// the zones that she is in at the start of the frame change her movement
+	zoneBounds := char.Position
 
 	// decelerate to 0
 	if char.SpeedX > 0 {
@@ -305,27 +581,96 @@ func (g *Game) updateCharacter(charIndex int) {
 		}
 	}
 
+	// holding down while standing on TopSolid objects drops her through them
+	if char.dropFrames > 0 {
+		char.dropFrames--
+		if char.dropFrames == 0 {
+			char.dropThrough = nil
+		}
+	}
+	if inputState.downDown && !char.InAir {
+		if objects, ok := g.dropThroughObjects(char.Position); ok {
+			char.dropThrough = objects
+			char.dropFrames = DropThroughFrames
+		}
+	}
+
+	// wall slide: in the air she can push against a wall to slide down and
+	// jump off of it, wallDx is the direction of the wall, 0 if there is none
+	wallDx := 0
+	if char.InAir && inputState.leftDown != inputState.rightDown {
+		wallDx = 1
+		if inputState.leftDown {
+			wallDx = -1
+		}
+		if _, touching := g.MoveInX(char.Position, wallDx); !touching {
+			wallDx = 0
+		}
+	}
+
 	// mustJumpThisFrame is for avoiding jumping again after a jump is over.
 	// If you press jump and keep holding it until you land, you should not
 	// launch into the next jump right away. Only when you release the jump
 	// button and press it again will you launch another jump
-	if inputState.mustJumpThisFrame && !char.InAir {
+	//
+	// a jump can be done a few frames after walking off a ledge (coyote time)
+	// and a jump that is pressed a few frames before landing is done when
+	// landing (jump buffering)
+	wantsJump := inputState.mustJumpThisFrame || char.jumpBuffer > 0
+	canJump := !char.InAir || char.coyoteFrames > 0
+	if char.jumpBuffer > 0 {
+		char.jumpBuffer--
+	}
+	if wantsJump && canJump {
 		char.SpeedY = char.Params.InitialJumpSpeedY
+		char.coyoteFrames = 0
+		char.jumpBuffer = 0
+	} else if inputState.mustJumpThisFrame && wallDx != 0 &&
+		char.Params.WallJumpSpeedX != 0 {
+		char.SpeedX = -wallDx * char.Params.WallJumpSpeedX
+		char.SpeedY = char.Params.WallJumpSpeedY
+		wallDx = 0
+	} else if inputState.mustJumpThisFrame {
+		char.jumpBuffer = char.Params.JumpBufferFrames
 	}
 	inputState.mustJumpThisFrame = false
 
 	goingUp := char.SpeedY < 0
 	if goingUp && inputState.jumpDown {
 		// make her jump higher if holding jump while going up
-		char.SpeedY += char.Params.LowGravity
+		char.SpeedY += g.zoneGravity(zoneBounds, char.Params.LowGravity)
 	} else {
-		char.SpeedY += char.Params.HighGravity
+		char.SpeedY += g.zoneGravity(zoneBounds, char.Params.HighGravity)
 	}
 	if char.SpeedY > char.Params.MaxSpeedY {
 		char.SpeedY = char.Params.MaxSpeedY
 	}
+	char.wallSliding = wallDx != 0 && char.Params.WallSlideSpeedY != 0 &&
+		char.SpeedY > 0
+	if char.wallSliding && char.SpeedY > char.Params.WallSlideSpeedY {
+		char.SpeedY = char.Params.WallSlideSpeedY
+	}
+	g.capZoneSpeed(zoneBounds, char)
 
-	char.Update(g)
+	wasOnGround := !char.InAir
+	if char.dropFrames > 0 {
+		char.Update(droppingCollider{g, char.dropThrough})
+	} else {
+		char.Update(g)
+	}
+	if wind := g.zoneWind(zoneBounds); wind != 0 {
+		char.Position, _ = g.MoveInX(char.Position, subPixelMove(&char.subX, wind))
+	}
+	g.bounce(char, inputState.jumpDown)
+
+	if !char.InAir {
+		char.coyoteFrames = 0
+	} else if wasOnGround && char.SpeedY >= 0 && char.dropFrames == 0 {
+		// she walked off a ledge
+		char.coyoteFrames = char.Params.CoyoteFrames
+	} else if char.coyoteFrames > 0 {
+		char.coyoteFrames--
+	}
 }
 
 func (g *Game) MoveInX(bounds Rectangle, dx int) (newBounds Rectangle, collided bool) {
@@ -336,8 +681,8 @@ func (g *Game) MoveInX(bounds Rectangle, dx int) (newBounds Rectangle, collided
 		moveSpace := bounds
 		moveSpace.X += dx
 		moveSpace.W -= dx // make it wider, dx is negative
-		for i := range g.objects {
-			if g.objects[i].Solidness == Solid &&
+		for _, i := range g.nearObjects(moveSpace) {
+			if g.objects[i].blocksX(bounds, dx) &&
 				g.objects[i].Bounds.Overlaps(moveSpace) {
 				collided = true
 				overlap := g.objects[i].Bounds.X + g.objects[i].Bounds.W - moveSpace.X
@@ -350,8 +695,8 @@ func (g *Game) MoveInX(bounds Rectangle, dx int) (newBounds Rectangle, collided
 	if dx > 0 {
 		moveSpace := bounds
 		moveSpace.W += dx
-		for i := range g.objects {
-			if g.objects[i].Solidness == Solid &&
+		for _, i := range g.nearObjects(moveSpace) {
+			if g.objects[i].blocksX(bounds, dx) &&
 				g.objects[i].Bounds.Overlaps(moveSpace) {
 				collided = true
 				overlap := moveSpace.X + moveSpace.W - g.objects[i].Bounds.X
@@ -364,13 +709,18 @@ func (g *Game) MoveInX(bounds Rectangle, dx int) (newBounds Rectangle, collided
 }
 
 func (g *Game) MoveInY(bounds Rectangle, dy int) (newBounds Rectangle, collided bool) {
+	return g.moveInY(bounds, dy, nil)
+}
+
+// moveInY is MoveInY but falling through the objects in dropThrough.
+func (g *Game) moveInY(bounds Rectangle, dy int, dropThrough []int) (newBounds Rectangle, collided bool) {
 	newBounds = bounds.MoveBy(0, dy)
 	if dy < 0 {
 		moveSpace := bounds
 		moveSpace.Y += dy
 		moveSpace.H -= dy // make it wider, dy is negative
-		for i := range g.objects {
-			if g.objects[i].Solidness == Solid &&
+		for _, i := range g.nearObjects(moveSpace) {
+			if g.objects[i].blocksUp(bounds) &&
 				g.objects[i].Bounds.Overlaps(moveSpace) {
 				collided = true
 				overlap := g.objects[i].Bounds.Y + g.objects[i].Bounds.H - moveSpace.Y
@@ -388,25 +738,65 @@ func (g *Game) MoveInY(bounds Rectangle, dy int) (newBounds Rectangle, collided
 		moveSpace := bounds
 		moveSpace.Y += bounds.H
 		moveSpace.H = dy
-		for i := range g.objects {
+		x := bounds.X + bounds.W/2
+		for _, i := range g.nearObjects(moveSpace) {
+			if contains(dropThrough, i) {
+				continue
+			}
 			objBounds := g.objects[i].Bounds
 			objBounds.H = 1
+			if g.objects[i].Slope != NoSlope {
+				// slopes are landed on with the bottom center, see below, but
+				// falling beside a Solid slope you land on its corner instead
+				// of sliding down inside of it
+				corner, ok := g.objects[i].cornerBeside(x)
+				if !ok {
+					continue
+				}
+				objBounds = corner
+			}
 			if objBounds.Overlaps(moveSpace) {
 				collided = true
-				overlap := moveSpace.Y + moveSpace.H - g.objects[i].Bounds.Y
+				overlap := moveSpace.Y + moveSpace.H - objBounds.Y
 				moveSpace.H -= overlap
 			}
 		}
+		// slopes stop you when your bottom center crosses their surface
+		if y, found := g.SlopeSurface(x, moveSpace.Y, moveSpace.Y+moveSpace.H-1); found {
+			collided = true
+			moveSpace.H = y - moveSpace.Y
+		}
 		newBounds = bounds.MoveBy(0, moveSpace.H)
 	}
 	return
 }
 
+// SlopeSurface returns the highest slope surface at column x that lies in the
+// range [top, bottom]. found is false if there is none.
+func (g *Game) SlopeSurface(x, top, bottom int) (y int, found bool) {
+	if bottom < top {
+		return 0, false
+	}
+	for _, i := range g.nearObjects(Rectangle{x, top, 1, bottom - top + 1}) {
+		obj := &g.objects[i]
+		if obj.Slope == NoSlope || x < obj.Bounds.X || x >= obj.Bounds.X+obj.Bounds.W {
+			continue
+		}
+		surface := obj.surfaceAt(x)
+		if top <= surface && surface <= bottom && (!found || surface < y) {
+			y, found = surface, true
+		}
+	}
+	return
+}
+
 func (g *Game) Running() bool {
 	return g.running
 }
 
-func (g *Game) Render() {
+// Render draws the game state between the last two updates, see
+// fixedStepLoop.advance for the meaning of alpha.
+func (g *Game) Render(alpha float64) {
 	if g.state == IntroPCScene {
 		x, y := 1000, 0
 		g.camera.CenterAround(x, y)
@@ -423,11 +813,27 @@ func (g *Game) Render() {
 		w, h := img.Size()
 		img.DrawAt(x-w/2, y-h/2)
 	} else {
+		g.camera.CenterAround(
+			g.characters[g.cameraTarget].renderPosition(alpha).Center(),
+		)
+
 		for i := range g.imageObjects {
 			g.imageObjects[i].Render()
 		}
-
-		g.characters[1].Render()
-		g.characters[0].Render()
+		g.renderCollectibles()
+		g.renderPlatforms(alpha)
+		g.renderBouncePads()
+		g.renderHazards()
+
+		g.ghost.render(alpha)
+		g.characters[1].Render(alpha)
+		g.characters[0].Render(alpha)
+
+		g.timer.render(g.graphics)
+		g.renderCollectedCount()
+		if g.rewinding {
+			drawText(g.graphics, "<< rewind", 20, 120, hudPixelSize, 255, 255, 255)
+		}
+		g.renderHint()
 	}
 }
@@ -1,5 +1,7 @@
 package main
 
+import "math"
+
 type Rectangle struct {
 	X, Y, W, H int
 }
@@ -29,6 +31,15 @@ func (r Rectangle) Contains(o Rectangle) bool {
 	return o.X >= r.X && o.Y >= r.Y && o.X+o.W <= r.X+r.W && o.Y+o.H <= r.Y+r.H
 }
 
+// Interpolate returns the rectangle between r (alpha = 0) and to (alpha = 1),
+// rounded to whole pixels.
+func (r Rectangle) Interpolate(to Rectangle, alpha float64) Rectangle {
+	lerp := func(a, b int) int {
+		return a + int(math.Floor(0.5+alpha*float64(b-a)))
+	}
+	return Rectangle{lerp(r.X, to.X), lerp(r.Y, to.Y), lerp(r.W, to.W), lerp(r.H, to.H)}
+}
+
 type Point struct {
 	X, Y int
 }
@@ -0,0 +1,111 @@
+package main
+
+// GhostOpacity is how opaque the ghost runner is drawn, 0 is invisible and 1
+// is fully opaque.
+const GhostOpacity = 0.4
+
+// ghostRunner records the hero's inputs in every attempt and keeps the fastest
+// run that reached the goal. In later attempts this run is played back on a
+// third character, the ghost, which the player can race against. The ghost
+// collides with the level but not with the other characters and it has no
+// influence on the game.
+type ghostRunner struct {
+	enabled bool
+
+	// attempt records the hero's current attempt, attemptStart is the hero's
+	// input state at the start of the attempt
+	attempt      *InputRecorder
+	attemptStart inputState
+	// discarded attempts are not kept even if they reach the goal
+	discarded bool
+
+	best       []inputRecord
+	bestStart  inputState
+	bestFrames int
+
+	char   *Character
+	input  inputState
+	player *InputPlayer
+}
+
+func newGhostRunner(assets AssetLoader) *ghostRunner {
+	ghost := &ghostRunner{
+		enabled: true,
+		attempt: NewInputRecorder(),
+		char:    NewHero(assets),
+	}
+	ghost.char.ghost = true
+	ghost.attempt.Start(0)
+	return ghost
+}
+
+// disable stops recording attempts and hides the ghost, e.g. when watching a
+// replay.
+func (r *ghostRunner) disable() {
+	r.enabled = false
+	r.attempt.Stop()
+	r.player = nil
+}
+
+func (r *ghostRunner) record(event InputEvent) {
+	r.attempt.Record(event)
+}
+
+func (r *ghostRunner) nextFrame() {
+	r.attempt.NextFrame()
+}
+
+// startAttempt is called before every attempt, heroInput is the hero's input
+// state at the start and start is where the ghost starts running.
+func (r *ghostRunner) startAttempt(heroInput inputState, start Point) {
+	if !r.enabled {
+		return
+	}
+	r.attempt.Restart()
+	r.attemptStart = heroInput
+	r.discarded = false
+
+	if r.best != nil {
+		r.player = NewInputPlayer(r.best)
+		r.input = r.bestStart
+		r.char.SetBottomCenterTo(start.X, start.Y)
+		r.char.Reset(RightDirectionIndex)
+	}
+}
+
+// discardAttempt is called when the current attempt can not be played back,
+// e.g. because the hero respawned during it.
+func (r *ghostRunner) discardAttempt() {
+	r.discarded = true
+}
+
+// finishAttempt is called when the hero reaches the goal after the given
+// number of frames. The attempt is kept if it is the fastest so far.
+func (r *ghostRunner) finishAttempt(frames int) {
+	if !r.enabled || r.discarded {
+		return
+	}
+	if r.best == nil || frames < r.bestFrames {
+		r.best = r.attempt.Records()
+		r.bestStart = r.attemptStart
+		r.bestFrames = frames
+	}
+}
+
+func (r *ghostRunner) play(frame int) {
+	if r.player != nil {
+		r.player.Play(frame, r.input.apply)
+	}
+}
+
+func (r *ghostRunner) update(g *Game) {
+	if r.player != nil {
+		g.moveCharacter(r.char, &r.input)
+	}
+}
+
+func (r *ghostRunner) render(alpha float64) {
+	if r.player != nil {
+		r.char.Render(alpha)
+	}
+}
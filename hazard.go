@@ -0,0 +1,89 @@
+package main
+
+// Hazard is the kind of a deadly level object. Hazards do not stop characters,
+// touching one kills the hero and has Barney respawn. What happens after the
+// hero dies depends on the level's RespawnMode.
+type Hazard int
+
+const (
+	NoHazard Hazard = iota
+	Spikes
+	Thorns
+	Water
+)
+
+// String returns the constant's name, the level editor writes these into the
+// level files.
+func (h Hazard) String() string {
+	switch h {
+	case NoHazard:
+		return "NoHazard"
+	case Spikes:
+		return "Spikes"
+	case Thorns:
+		return "Thorns"
+	case Water:
+		return "Water"
+	default:
+		return "unknown hazard"
+	}
+}
+
+type hazardObject struct {
+	Bounds Rectangle
+	Kind   Hazard
+	// image is tiled over the bounds, it is nil for invisible hazards
+	image Image
+}
+
+// hazardAt returns the hazard that bounds touches, or NoHazard.
+func (g *Game) hazardAt(bounds Rectangle) Hazard {
+	for i := range g.hazards {
+		if g.hazards[i].Bounds.Overlaps(bounds) {
+			return g.hazards[i].Kind
+		}
+	}
+	return NoHazard
+}
+
+func (g *Game) killHero() {
+	g.fallingSound.PlayOnce()
+	if g.respawnMode == RespawnAtCheckpoint {
+		g.respawnHero()
+		return
+	}
+	g.state = PlayerDying
+	g.playerDyingCountDown = PlayerDyingDelay
+}
+
+// respawnBarney puts Barney back at the start and plays his recorded inputs
+// from their beginning again.
+func (g *Game) respawnBarney() {
+	barney := g.characters[1]
+	barney.SetBottomCenterTo(startPositions[1].X, startPositions[1].Y)
+	barney.Reset(RightDirectionIndex)
+	g.inputStates[1] = inputState{}
+	g.aiPlayer.Rewind()
+	g.aiStartFrame = g.frame
+}
+
+// renderHazards tiles the hazards' images over their bounds, the last row and
+// column of tiles may stick out a bit.
+func (g *Game) renderHazards() {
+	for i := range g.hazards {
+		h := &g.hazards[i]
+		if h.image == nil {
+			continue
+		}
+		w, height := h.image.Size()
+		if w <= 0 || height <= 0 {
+			continue
+		}
+		b := h.Bounds
+		for y := b.Y; y < b.Y+b.H; y += height {
+			for x := b.X; x < b.X+b.W; x += w {
+				h.image.DrawAt(x, y)
+			}
+		}
+	}
+}
@@ -13,6 +13,8 @@ const (
 	GoRight
 	Jump
 	QuitGame
+	Rewind
+	GoDown
 )
 
 func (a InputAction) String() string {
@@ -25,6 +27,10 @@ func (a InputAction) String() string {
 		return "Jump"
 	case QuitGame:
 		return "QuitGame"
+	case Rewind:
+		return "Rewind"
+	case GoDown:
+		return "GoDown"
 	default:
 		return "unknown input"
 	}
@@ -1,56 +1,147 @@
 package main
 
-import (
-	"bytes"
-	"fmt"
-	"io/ioutil"
-)
-
-var (
-	recordingInput         = false
-	replayingInput         = false
-	recordedCharacterIndex = 1
-)
-
+// inputRecord is an input event together with the race frame it happened in.
+// The fields are exported so they can be stored in replay files.
 type inputRecord struct {
-	frame int
-	event InputEvent
+	Frame int
+	Event InputEvent
+}
+
+// InputRecorder records the input events of a set of characters together with
+// the frame in which they happened. The frame counter is advanced by the Game
+// once per simulated race frame.
+type InputRecorder struct {
+	recording  bool
+	characters []int
+	frame      int
+	records    []inputRecord
+	// every hashInterval frames a state check is recorded, 0 means never
+	hashInterval int
+	checks       []StateCheck
+}
+
+func NewInputRecorder() *InputRecorder {
+	return &InputRecorder{}
+}
+
+// Start discards all previous records and starts recording the inputs for the
+// given characters, beginning at frame 0.
+func (r *InputRecorder) Start(characterIndices ...int) {
+	r.recording = true
+	r.characters = append(r.characters[:0], characterIndices...)
+	r.Restart()
+}
+
+// Stop ends the recording, the records made so far are kept.
+func (r *InputRecorder) Stop() {
+	r.recording = false
+}
+
+// Restart discards all records and resets the frame counter to 0 but keeps
+// recording if the recorder was recording before.
+func (r *InputRecorder) Restart() {
+	r.frame = 0
+	// snapshots share the old lists, new ones must not overwrite them
+	r.records = nil
+	r.checks = nil
 }
 
-var (
-	inputs []inputRecord
-	frame  int
-)
+// SetHashInterval makes the recorder store a StateCheck every n frames, see
+// Check. Pass 0 to turn the state checks off.
+func (r *InputRecorder) SetHashInterval(n int) {
+	r.hashInterval = n
+}
 
-func init() {
-	if replayingInput {
-		inputs = recordedInputs
+func (r *InputRecorder) HashInterval() int {
+	return r.hashInterval
+}
+
+// Check records the state if the current frame is a multiple of the hash
+// interval. It is called by the Game at the end of every race frame.
+func (r *InputRecorder) Check(state SimState) {
+	if r.recording && r.hashInterval > 0 && r.frame%r.hashInterval == 0 {
+		r.checks = append(r.checks, StateCheck{state.Hash(), state})
 	}
 }
 
-func recordInput(event InputEvent) {
-	if recordingInput && event.CharacterIndex == recordedCharacterIndex {
-		inputs = append(inputs, inputRecord{frame: frame, event: event})
+// Checks returns a copy of all state checks recorded so far.
+func (r *InputRecorder) Checks() []StateCheck {
+	checks := make([]StateCheck, len(r.checks))
+	copy(checks, r.checks)
+	return checks
+}
+
+func (r *InputRecorder) Recording() bool {
+	return r.recording
+}
+
+// Characters returns the indices of the characters being recorded.
+func (r *InputRecorder) Characters() []int {
+	return r.characters
+}
+
+func (r *InputRecorder) Frame() int {
+	return r.frame
+}
+
+func (r *InputRecorder) NextFrame() {
+	r.frame++
+}
+
+// Records returns a copy of all inputs recorded so far.
+func (r *InputRecorder) Records() []inputRecord {
+	records := make([]inputRecord, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+func (r *InputRecorder) Record(event InputEvent) {
+	if !r.recording {
+		return
+	}
+	for _, index := range r.characters {
+		if index == event.CharacterIndex {
+			r.records = append(r.records, inputRecord{Frame: r.frame, Event: event})
+			return
+		}
 	}
 }
 
-func saveRecordedInputs() {
-	input := bytes.NewBuffer(nil)
-	input.WriteString(`package main
-
-var recordedInputs = []inputRecord{
-`)
-	for i := range inputs {
-		fmt.Fprintf(
-			input,
-			"\t{%v, InputEvent{%v, %v, %v}},\n",
-			inputs[i].frame,
-			inputs[i].event.Action,
-			inputs[i].event.Pressed,
-			inputs[i].event.CharacterIndex,
-		)
+// InputPlayer plays back recorded inputs frame by frame.
+type InputPlayer struct {
+	records []inputRecord
+	next    int
+}
+
+func NewInputPlayer(records []inputRecord) *InputPlayer {
+	return &InputPlayer{records: records}
+}
+
+// Play calls handle for every recorded event up to and including the given
+// frame that has not been played yet.
+func (p *InputPlayer) Play(frame int, handle func(InputEvent)) {
+	for p.next < len(p.records) && p.records[p.next].Frame <= frame {
+		handle(p.records[p.next].Event)
+		p.next++
 	}
-	input.WriteString(`}
-`)
-	ioutil.WriteFile("./recorded_inputs.go", input.Bytes(), 0777)
+}
+
+// Cursor is the index of the next record to be played.
+func (p *InputPlayer) Cursor() int {
+	return p.next
+}
+
+// Seek sets the index of the next record to be played.
+func (p *InputPlayer) Seek(cursor int) {
+	p.next = cursor
+}
+
+// Rewind starts the playback over from the first record.
+func (p *InputPlayer) Rewind() {
+	p.next = 0
+}
+
+// Done is true when all records have been played.
+func (p *InputPlayer) Done() bool {
+	return p.next >= len(p.records)
 }
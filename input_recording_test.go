@@ -0,0 +1,37 @@
+// +build headless
+
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestLongRecordingWithStateChecksAllocatesLinearly(t *testing.T) {
+	game, _ := newTestGame(0)
+	// without Barney's run nobody wins and the race goes on
+	game.SetAIInputs(nil)
+	game.recorder.Start(0)
+	game.recorder.SetHashInterval(1)
+	for game.state != Playing {
+		game.Update()
+	}
+
+	const frames = 8000
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < frames; i++ {
+		game.Update()
+	}
+	runtime.ReadMemStats(&after)
+
+	if game.state != Playing || len(game.recorder.checks) < frames {
+		t.Fatalf("want %v frames of racing with a check each, have state %v and %v checks",
+			frames, game.state, len(game.recorder.checks))
+	}
+	const maxBytes = 64 << 20
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > maxBytes {
+		t.Errorf("recording %v frames allocated %v MB, want at most %v MB",
+			frames, allocated>>20, maxBytes>>20)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import "fmt"
+
+type Rectangle struct {
+	X, Y, W, H int
+}
+
+// TriggerEvent, TriggerAction and ActionKind mirror the game's trigger types,
+// the String methods write the constants into the level files.
+type TriggerEvent int
+
+const (
+	OnEnter TriggerEvent = iota
+	OnLeave
+)
+
+func (e TriggerEvent) String() string {
+	switch e {
+	case OnEnter:
+		return "OnEnter"
+	case OnLeave:
+		return "OnLeave"
+	default:
+		return "unknown trigger event"
+	}
+}
+
+type TriggerAction struct {
+	Kind   ActionKind
+	ID     string
+	Bounds Rectangle
+	Point  Point
+}
+
+type ActionKind int
+
+const (
+	PlaySound ActionKind = iota
+	SetCameraBounds
+	SetCheckpoint
+	ShowHint
+	StartCutScene
+	HeroWins
+	BarneyWins
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case PlaySound:
+		return "PlaySound"
+	case SetCameraBounds:
+		return "SetCameraBounds"
+	case SetCheckpoint:
+		return "SetCheckpoint"
+	case ShowHint:
+		return "ShowHint"
+	case StartCutScene:
+		return "StartCutScene"
+	case HeroWins:
+		return "HeroWins"
+	case BarneyWins:
+		return "BarneyWins"
+	default:
+		return "unknown action"
+	}
+}
+
+// String writes the action as a Go composite literal with only the fields
+// that are set.
+func (a TriggerAction) String() string {
+	s := "{Kind: " + a.Kind.String()
+	if a.ID != "" {
+		s += fmt.Sprintf(", ID: %q", a.ID)
+	}
+	if a.Bounds != (Rectangle{}) {
+		s += fmt.Sprintf(", Bounds: Rectangle{%v, %v, %v, %v}",
+			a.Bounds.X, a.Bounds.Y, a.Bounds.W, a.Bounds.H)
+	}
+	if a.Point != (Point{}) {
+		s += fmt.Sprintf(", Point: Point{%v, %v}", a.Point.X, a.Point.Y)
+	}
+	return s + "}"
+}
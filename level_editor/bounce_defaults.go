@@ -0,0 +1,35 @@
+package main
+
+// new bounce pads launch a bit higher than a jump, the speeds are in the game's
+// sub-pixels, 256 per pixel
+const (
+	defaultBounceSpeedY    = -30 * 256
+	defaultBounceJumpBonus = -5 * 256
+	defaultBounceSound     = "bounce"
+	bounceSpeedStep        = 256
+)
+
+// a bounce pad's image and sound are picked by cycling through these, a pad
+// without an image is invisible in the game
+var (
+	bouncePadImages = []string{
+		"",
+		"grass center 1",
+		"grass center 2",
+		"grass center 3",
+		"ground center 1",
+		"square rock",
+	}
+	bouncePadSounds = []string{"bounce", "whistle", "win"}
+)
+
+// nextID returns the ID after the current one, or the first one if current is
+// not in the list.
+func nextID(ids []string, current string) string {
+	for i, id := range ids {
+		if id == current {
+			return ids[(i+1)%len(ids)]
+		}
+	}
+	return ids[0]
+}
@@ -0,0 +1,11 @@
+package main
+
+type LevelBouncePad struct {
+	X, Y, W, H int
+	SpeedY     int
+	JumpBonus  int
+	Image      LevelImage
+	Sound      string
+}
+
+var LevelBouncePads = []LevelBouncePad{}
@@ -0,0 +1,7 @@
+package main
+
+type LevelCheckpoint struct {
+	X, Y, W, H int
+}
+
+var LevelCheckpoints = []LevelCheckpoint{}
@@ -0,0 +1,8 @@
+package main
+
+type LevelCollectible struct {
+	ID   string
+	X, Y int
+}
+
+var LevelCollectibles = []LevelCollectible{}
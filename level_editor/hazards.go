@@ -0,0 +1,43 @@
+package main
+
+// Hazard mirrors the game's hazard kinds, its String method writes them into
+// the level files.
+type Hazard int
+
+const (
+	NoHazard Hazard = iota
+	Spikes
+	Thorns
+	Water
+	hazardCount
+)
+
+func (h Hazard) String() string {
+	switch h {
+	case NoHazard:
+		return "NoHazard"
+	case Spikes:
+		return "Spikes"
+	case Thorns:
+		return "Thorns"
+	case Water:
+		return "Water"
+	default:
+		return "unknown hazard"
+	}
+}
+
+// defaultImage is the ID of the image that is tiled over new hazards of this
+// kind in the game.
+func (h Hazard) defaultImage() string {
+	switch h {
+	case Spikes:
+		return "spikes"
+	case Thorns:
+		return "thorns"
+	case Water:
+		return "water"
+	default:
+		return ""
+	}
+}
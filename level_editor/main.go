@@ -17,14 +17,18 @@ func init() {
 }
 
 var (
-	renderer       *sdl.Renderer
-	backColor      = [3]uint8{0, 95, 83}
-	cameraX        = 0
-	cameraY        = 0
-	draggingImage  = false
-	draggingObject = false
-	images         []image
-	resources      *blob.Blob
+	renderer           *sdl.Renderer
+	backColor          = [3]uint8{0, 95, 83}
+	cameraX            = 0
+	cameraY            = 0
+	draggingImage      = false
+	draggingObject     = false
+	draggingPlatform   = false
+	draggingCheckpoint = false
+	draggingZone       = false
+	draggingBouncePad  = false
+	images             []image
+	resources          *blob.Blob
 )
 
 func main() {
@@ -64,22 +68,30 @@ func main() {
 			"grass center 3",
 			"small tree",
 		} {
-			images = append(images, image{id, loadImage(id), 0, i * 50})
+			images = append(images, image{id, loadImage(id), 0, i * 50, false})
 		}
 	} else {
 		for i := range LevelImages {
 			id := LevelImages[i].ID
 			x, y := LevelImages[i].X, LevelImages[i].Y
 			img := loadImage(id)
-			images = append(images, image{id, img, x, y})
+			images = append(images, image{id, img, x, y, false})
 		}
 	}
+	// the collectibles are edited like the images
+	for _, c := range LevelCollectibles {
+		images = append(images, image{c.ID, loadImage(c.ID), c.X, c.Y, true})
+	}
 
 	leftDown := false
 	middleDown := false
 	rightDown := false
 	selectedImage := -1
 	selectedObject := -1
+	selectedPlatform := -1
+	selectedCheckpoint := -1
+	selectedZone := -1
+	selectedBouncePad := -1
 	var lastX, lastY int
 
 	moveImage := func(dx, dy int) {
@@ -87,6 +99,12 @@ func main() {
 			images[selectedImage].x += dx
 			images[selectedImage].y += dy
 		}
+		// a bounce pad's image is moved relative to the pad
+		if selectedBouncePad != -1 {
+			img := &LevelBouncePads[selectedBouncePad].Image
+			img.X += dx
+			img.Y += dy
+		}
 	}
 
 	stretchObject := func(dx, dy int) {
@@ -113,9 +131,17 @@ func main() {
 					if !leftDown {
 						draggingImage = false
 						draggingObject = false
+						draggingPlatform = false
+						draggingCheckpoint = false
+						draggingZone = false
+						draggingBouncePad = false
 					} else {
 						selectedObject = -1
 						selectedImage = -1
+						selectedPlatform = -1
+						selectedCheckpoint = -1
+						selectedZone = -1
+						selectedBouncePad = -1
 						for i := range images {
 							if images[i].contains(
 								int(event.X)-cameraX,
@@ -137,6 +163,58 @@ func main() {
 								}
 							}
 						}
+
+						if selectedImage == -1 && selectedObject == -1 {
+							for i := range LevelPlatforms {
+								if contains(platformObject(LevelPlatforms[i]),
+									int(event.X)-cameraX,
+									int(event.Y)-cameraY,
+								) {
+									draggingPlatform = true
+									selectedPlatform = i
+								}
+							}
+						}
+
+						if selectedImage == -1 && selectedObject == -1 &&
+							selectedPlatform == -1 {
+							for i := range LevelCheckpoints {
+								if contains(checkpointObject(LevelCheckpoints[i]),
+									int(event.X)-cameraX,
+									int(event.Y)-cameraY,
+								) {
+									draggingCheckpoint = true
+									selectedCheckpoint = i
+								}
+							}
+						}
+
+						if selectedImage == -1 && selectedObject == -1 &&
+							selectedPlatform == -1 && selectedCheckpoint == -1 {
+							for i := range LevelZones {
+								if contains(zoneObject(LevelZones[i]),
+									int(event.X)-cameraX,
+									int(event.Y)-cameraY,
+								) {
+									draggingZone = true
+									selectedZone = i
+								}
+							}
+						}
+
+						if selectedImage == -1 && selectedObject == -1 &&
+							selectedPlatform == -1 && selectedCheckpoint == -1 &&
+							selectedZone == -1 {
+							for i := range LevelBouncePads {
+								if contains(bouncePadObject(LevelBouncePads[i]),
+									int(event.X)-cameraX,
+									int(event.Y)-cameraY,
+								) {
+									draggingBouncePad = true
+									selectedBouncePad = i
+								}
+							}
+						}
 					}
 				}
 				if event.Button == sdl.BUTTON_MIDDLE {
@@ -150,6 +228,9 @@ func main() {
 						0,
 						0,
 						true,
+						NoSlope,
+						NoHazard,
+						"",
 					})
 					selectedObject = -1
 				}
@@ -165,6 +246,28 @@ func main() {
 					obj.X += dx
 					obj.Y += dy
 				}
+				if selectedPlatform != -1 && draggingPlatform {
+					path := LevelPlatforms[selectedPlatform].Path
+					for i := range path {
+						path[i].X += dx
+						path[i].Y += dy
+					}
+				}
+				if selectedCheckpoint != -1 && draggingCheckpoint {
+					c := &LevelCheckpoints[selectedCheckpoint]
+					c.X += dx
+					c.Y += dy
+				}
+				if selectedZone != -1 && draggingZone {
+					z := &LevelZones[selectedZone]
+					z.X += dx
+					z.Y += dy
+				}
+				if selectedBouncePad != -1 && draggingBouncePad {
+					pad := &LevelBouncePads[selectedBouncePad]
+					pad.X += dx
+					pad.Y += dy
+				}
 				lastX, lastY = int(event.X), int(event.Y)
 
 				if middleDown {
@@ -232,6 +335,161 @@ func main() {
 						obj := &LevelObjects[selectedObject]
 						obj.Solid = !obj.Solid
 					}
+				case sdl.K_r:
+					if selectedObject != -1 {
+						obj := &LevelObjects[selectedObject]
+						obj.Slope = (obj.Slope + 1) % slopeCount
+					}
+				case sdl.K_h:
+					if selectedObject != -1 {
+						obj := &LevelObjects[selectedObject]
+						obj.Hazard = (obj.Hazard + 1) % hazardCount
+						obj.Image = obj.Hazard.defaultImage()
+					}
+				case sdl.K_p:
+					// turn the selected object into a moving platform
+					if selectedObject != -1 {
+						obj := LevelObjects[selectedObject]
+						LevelPlatforms = append(LevelPlatforms, LevelPlatform{
+							W:     obj.W,
+							H:     obj.H,
+							Solid: obj.Solid,
+							Path:  []Point{{obj.X, obj.Y}},
+							Speed: 2,
+						})
+						LevelObjects = append(
+							LevelObjects[:selectedObject],
+							LevelObjects[selectedObject+1:]...,
+						)
+						selectedObject = -1
+						selectedPlatform = len(LevelPlatforms) - 1
+					}
+				case sdl.K_x:
+					// turn the selected object into a checkpoint
+					if selectedObject != -1 {
+						obj := LevelObjects[selectedObject]
+						LevelCheckpoints = append(LevelCheckpoints, LevelCheckpoint{
+							obj.X, obj.Y, obj.W, obj.H,
+						})
+						LevelObjects = append(
+							LevelObjects[:selectedObject],
+							LevelObjects[selectedObject+1:]...,
+						)
+						selectedObject = -1
+						selectedCheckpoint = len(LevelCheckpoints) - 1
+					}
+				case sdl.K_z:
+					// turn the selected object into a physics zone
+					if selectedObject != -1 {
+						obj := LevelObjects[selectedObject]
+						LevelZones = append(LevelZones, LevelZone{
+							X:              obj.X,
+							Y:              obj.Y,
+							W:              obj.W,
+							H:              obj.H,
+							GravityPercent: 100,
+						})
+						LevelObjects = append(
+							LevelObjects[:selectedObject],
+							LevelObjects[selectedObject+1:]...,
+						)
+						selectedObject = -1
+						selectedZone = len(LevelZones) - 1
+					}
+				case sdl.K_t:
+					// toggle between image and collectible
+					if selectedImage != -1 {
+						images[selectedImage].collectible = !images[selectedImage].collectible
+					}
+				case sdl.K_b:
+					// turn the selected object into a bounce pad
+					if selectedObject != -1 {
+						obj := LevelObjects[selectedObject]
+						LevelBouncePads = append(LevelBouncePads, LevelBouncePad{
+							X:         obj.X,
+							Y:         obj.Y,
+							W:         obj.W,
+							H:         obj.H,
+							SpeedY:    defaultBounceSpeedY,
+							JumpBonus: defaultBounceJumpBonus,
+							Sound:     defaultBounceSound,
+						})
+						LevelObjects = append(
+							LevelObjects[:selectedObject],
+							LevelObjects[selectedObject+1:]...,
+						)
+						selectedObject = -1
+						selectedBouncePad = len(LevelBouncePads) - 1
+					}
+				case sdl.K_e:
+					if selectedBouncePad != -1 {
+						img := &LevelBouncePads[selectedBouncePad].Image
+						img.ID = nextID(bouncePadImages, img.ID)
+						fmt.Printf("bounce pad image: %q\n", img.ID)
+					}
+				case sdl.K_q:
+					if selectedBouncePad != -1 {
+						pad := &LevelBouncePads[selectedBouncePad]
+						pad.Sound = nextID(bouncePadSounds, pad.Sound)
+						fmt.Printf("bounce pad sound: %q\n", pad.Sound)
+					}
+				case sdl.K_g:
+					if selectedZone != -1 {
+						z := &LevelZones[selectedZone]
+						z.GravityPercent = nextPreset(gravityPresets, z.GravityPercent)
+						fmt.Println("zone gravity:", z.GravityPercent, "%")
+					}
+				case sdl.K_v:
+					if selectedZone != -1 {
+						z := &LevelZones[selectedZone]
+						z.MaxSpeedX = nextPreset(maxSpeedPresets, z.MaxSpeedX)
+						z.MaxSpeedY = z.MaxSpeedX
+						fmt.Println("zone max speed:", z.MaxSpeedX)
+					}
+				case sdl.K_COMMA:
+					if selectedZone != -1 {
+						LevelZones[selectedZone].Wind -= windStep
+						fmt.Println("zone wind:", LevelZones[selectedZone].Wind)
+					}
+				case sdl.K_PERIOD:
+					if selectedZone != -1 {
+						LevelZones[selectedZone].Wind += windStep
+						fmt.Println("zone wind:", LevelZones[selectedZone].Wind)
+					}
+				case sdl.K_F4:
+					LevelRespawn = (LevelRespawn + 1) % respawnModeCount
+					fmt.Println("respawn mode:", LevelRespawn)
+				case sdl.K_F5:
+					LevelCharacterCollision = !LevelCharacterCollision
+					fmt.Println("character collision:", LevelCharacterCollision)
+				case sdl.K_o:
+					// add a waypoint under the mouse to the selected platform
+					if selectedPlatform != -1 {
+						p := &LevelPlatforms[selectedPlatform]
+						p.Path = append(p.Path, Point{lastX - cameraX, lastY - cameraY})
+					}
+				case sdl.K_m:
+					if selectedPlatform != -1 {
+						p := &LevelPlatforms[selectedPlatform]
+						p.Mode = (p.Mode + 1) % pathModeCount
+					}
+				case sdl.K_PAGEUP:
+					if selectedPlatform != -1 {
+						LevelPlatforms[selectedPlatform].Speed++
+					}
+					if selectedBouncePad != -1 {
+						// the pads launch upwards, their speeds are negative
+						LevelBouncePads[selectedBouncePad].SpeedY -= bounceSpeedStep
+						fmt.Println("bounce speed:", LevelBouncePads[selectedBouncePad].SpeedY)
+					}
+				case sdl.K_PAGEDOWN:
+					if selectedPlatform != -1 && LevelPlatforms[selectedPlatform].Speed > 0 {
+						LevelPlatforms[selectedPlatform].Speed--
+					}
+					if selectedBouncePad != -1 && LevelBouncePads[selectedBouncePad].SpeedY < 0 {
+						LevelBouncePads[selectedBouncePad].SpeedY += bounceSpeedStep
+						fmt.Println("bounce speed:", LevelBouncePads[selectedBouncePad].SpeedY)
+					}
 				case sdl.K_c:
 					if selectedImage != -1 {
 						copy := images[selectedImage]
@@ -249,6 +507,30 @@ func main() {
 							LevelObjects[selectedObject+1:]...,
 						)
 						selectedObject = -1
+					} else if selectedPlatform != -1 {
+						LevelPlatforms = append(
+							LevelPlatforms[:selectedPlatform],
+							LevelPlatforms[selectedPlatform+1:]...,
+						)
+						selectedPlatform = -1
+					} else if selectedCheckpoint != -1 {
+						LevelCheckpoints = append(
+							LevelCheckpoints[:selectedCheckpoint],
+							LevelCheckpoints[selectedCheckpoint+1:]...,
+						)
+						selectedCheckpoint = -1
+					} else if selectedZone != -1 {
+						LevelZones = append(
+							LevelZones[:selectedZone],
+							LevelZones[selectedZone+1:]...,
+						)
+						selectedZone = -1
+					} else if selectedBouncePad != -1 {
+						LevelBouncePads = append(
+							LevelBouncePads[:selectedBouncePad],
+							LevelBouncePads[selectedBouncePad+1:]...,
+						)
+						selectedBouncePad = -1
 					}
 				case sdl.K_F3:
 					saveLevel()
@@ -273,10 +555,40 @@ func main() {
 			if obj.Solid {
 				renderer.SetDrawColor(0, g, 255, a)
 			}
+			if obj.Hazard != NoHazard {
+				renderer.SetDrawColor(255, g, 0, a+50)
+			}
 			obj.X += cameraX
 			obj.Y += cameraY
-			r := sdl.Rect{int32(obj.X), int32(obj.Y), int32(obj.W), int32(obj.H)}
-			renderer.FillRect(&r)
+			if obj.Image != "" {
+				renderTiled(obj.Image, obj.X, obj.Y, obj.W, obj.H)
+			}
+			if obj.Slope == NoSlope {
+				r := sdl.Rect{int32(obj.X), int32(obj.Y), int32(obj.W), int32(obj.H)}
+				renderer.FillRect(&r)
+			} else {
+				renderSlope(obj)
+			}
+		}
+
+		for i, p := range LevelPlatforms {
+			renderPlatform(p, i == selectedPlatform)
+		}
+
+		for i, pad := range LevelBouncePads {
+			renderBouncePad(pad, i == selectedBouncePad)
+		}
+
+		for i, z := range LevelZones {
+			renderZone(z, i == selectedZone)
+		}
+
+		for i, c := range LevelCheckpoints {
+			renderCheckpoint(c, i == selectedCheckpoint)
+		}
+
+		for _, t := range LevelTriggers {
+			renderTrigger(t)
 		}
 
 		renderer.Present()
@@ -284,9 +596,10 @@ func main() {
 }
 
 type image struct {
-	id      string
-	texture *sdl.Texture
-	x, y    int
+	id          string
+	texture     *sdl.Texture
+	x, y        int
+	collectible bool
 }
 
 func (img image) contains(x, y int) bool {
@@ -306,6 +619,10 @@ func (img image) render(isSelected bool) {
 		renderer.SetDrawColor(0, 255, 0, 64)
 		renderer.FillRect(dest)
 	}
+	if img.collectible {
+		renderer.SetDrawColor(255, 220, 0, 255)
+		renderer.DrawRect(dest)
+	}
 }
 
 func check(err error) {
@@ -345,8 +662,10 @@ func imagesToString() string {
 	buffer := bytes.NewBuffer(nil)
 
 	for _, img := range images {
-		buffer.WriteString(fmt.Sprintf(`	{"%v", %v, %v},
+		if !img.collectible {
+			buffer.WriteString(fmt.Sprintf(`	{"%v", %v, %v},
 `, img.id, img.x, img.y))
+		}
 	}
 
 	return string(buffer.Bytes())
@@ -359,6 +678,9 @@ func saveObjects() {
 type LevelObject struct {
 	X, Y, W, H int
 	Solid      bool
+	Slope      Slope
+	Hazard     Hazard
+	Image      string
 }
 
 var LevelObjects = []LevelObject{
@@ -372,15 +694,393 @@ func objectsToString() string {
 	buffer := bytes.NewBuffer(nil)
 
 	for _, obj := range LevelObjects {
-		buffer.WriteString(fmt.Sprintf(`	{%v, %v, %v, %v, %v},
+		buffer.WriteString(fmt.Sprintf(`	{%v, %v, %v, %v, %v, %v, %v, %q},
 `,
-			obj.X, obj.Y, obj.W, obj.H, obj.Solid,
+			obj.X, obj.Y, obj.W, obj.H, obj.Solid, obj.Slope, obj.Hazard, obj.Image,
+		))
+	}
+
+	return string(buffer.Bytes())
+}
+
+func savePlatforms() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+type LevelPlatform struct {
+	W, H  int
+	Solid bool
+	Path  []Point
+	Speed int
+	Mode  PathMode
+	Image LevelImage
+}
+
+var LevelPlatforms = []LevelPlatform{` + platformsToString() + `}
+`)
+
+	ioutil.WriteFile("./platforms.go", buffer.Bytes(), 0777)
+}
+
+func platformsToString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	for _, p := range LevelPlatforms {
+		path := ""
+		for i, pt := range p.Path {
+			if i > 0 {
+				path += ", "
+			}
+			path += fmt.Sprintf("{%v, %v}", pt.X, pt.Y)
+		}
+		buffer.WriteString(fmt.Sprintf(`
+	{%v, %v, %v, []Point{%v}, %v, %v, LevelImage{"%v", %v, %v}},`,
+			p.W, p.H, p.Solid, path, p.Speed, p.Mode,
+			p.Image.ID, p.Image.X, p.Image.Y,
+		))
+	}
+	if len(LevelPlatforms) > 0 {
+		buffer.WriteString("\n")
+	}
+
+	return string(buffer.Bytes())
+}
+
+func saveTriggers() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+type LevelTrigger struct {
+	X, Y, W, H int
+	Character  int
+	Event      TriggerEvent
+	Actions    []TriggerAction
+}
+
+var LevelTriggers = []LevelTrigger{` + triggersToString() + `}
+`)
+
+	ioutil.WriteFile("./triggers.go", buffer.Bytes(), 0777)
+}
+
+func triggersToString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	for _, t := range LevelTriggers {
+		actions := ""
+		for i, a := range t.Actions {
+			if i > 0 {
+				actions += ", "
+			}
+			actions += a.String()
+		}
+		buffer.WriteString(fmt.Sprintf(`
+	{%v, %v, %v, %v, %v, %v, []TriggerAction{%v}},`,
+			t.X, t.Y, t.W, t.H, t.Character, t.Event, actions,
+		))
+	}
+	if len(LevelTriggers) > 0 {
+		buffer.WriteString("\n")
+	}
+
+	return string(buffer.Bytes())
+}
+
+// renderTrigger draws the outline of a trigger, the triggers are invisible in
+// the game.
+func renderTrigger(t LevelTrigger) {
+	renderer.SetDrawColor(255, 255, 0, 255)
+	r := sdl.Rect{int32(t.X + cameraX), int32(t.Y + cameraY), int32(t.W), int32(t.H)}
+	renderer.DrawRect(&r)
+}
+
+func saveCheckpoints() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+type LevelCheckpoint struct {
+	X, Y, W, H int
+}
+
+var LevelCheckpoints = []LevelCheckpoint{` + checkpointsToString() + `}
+`)
+
+	ioutil.WriteFile("./checkpoints.go", buffer.Bytes(), 0777)
+}
+
+func saveSettings() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+var LevelRespawn = ` + LevelRespawn.String() + `
+
+var LevelCharacterCollision = ` + fmt.Sprint(LevelCharacterCollision) + `
+`)
+
+	ioutil.WriteFile("./settings.go", buffer.Bytes(), 0777)
+}
+
+func checkpointsToString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	for _, c := range LevelCheckpoints {
+		buffer.WriteString(fmt.Sprintf(`
+	{%v, %v, %v, %v},`,
+			c.X, c.Y, c.W, c.H,
+		))
+	}
+	if len(LevelCheckpoints) > 0 {
+		buffer.WriteString("\n")
+	}
+
+	return string(buffer.Bytes())
+}
+
+func saveZones() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+type LevelZone struct {
+	X, Y, W, H     int
+	GravityPercent int
+	MaxSpeedX      int
+	MaxSpeedY      int
+	Wind           int
+}
+
+var LevelZones = []LevelZone{` + zonesToString() + `}
+`)
+
+	ioutil.WriteFile("./zones.go", buffer.Bytes(), 0777)
+}
+
+func zonesToString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	for _, z := range LevelZones {
+		buffer.WriteString(fmt.Sprintf(`
+	{%v, %v, %v, %v, %v, %v, %v, %v},`,
+			z.X, z.Y, z.W, z.H, z.GravityPercent, z.MaxSpeedX, z.MaxSpeedY, z.Wind,
 		))
 	}
+	if len(LevelZones) > 0 {
+		buffer.WriteString("\n")
+	}
+
+	return string(buffer.Bytes())
+}
+
+func saveBouncePads() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+type LevelBouncePad struct {
+	X, Y, W, H int
+	SpeedY     int
+	JumpBonus  int
+	Image      LevelImage
+	Sound      string
+}
+
+var LevelBouncePads = []LevelBouncePad{` + bouncePadsToString() + `}
+`)
+
+	ioutil.WriteFile("./bouncepads.go", buffer.Bytes(), 0777)
+}
+
+func bouncePadsToString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	for _, pad := range LevelBouncePads {
+		buffer.WriteString(fmt.Sprintf(`
+	{%v, %v, %v, %v, %v, %v, LevelImage{"%v", %v, %v}, "%v"},`,
+			pad.X, pad.Y, pad.W, pad.H, pad.SpeedY, pad.JumpBonus,
+			pad.Image.ID, pad.Image.X, pad.Image.Y, pad.Sound,
+		))
+	}
+	if len(LevelBouncePads) > 0 {
+		buffer.WriteString("\n")
+	}
 
 	return string(buffer.Bytes())
 }
 
+func bouncePadObject(pad LevelBouncePad) LevelObject {
+	return LevelObject{pad.X, pad.Y, pad.W, pad.H, false, NoSlope, NoHazard, ""}
+}
+
+// textures caches the images of bounce pads and hazards by their IDs.
+var textures = map[string]*sdl.Texture{}
+
+func cachedTexture(id string) *sdl.Texture {
+	texture, ok := textures[id]
+	if !ok {
+		texture = loadImage(id)
+		textures[id] = texture
+	}
+	return texture
+}
+
+// renderTiled repeats the image over the rectangle in screen coordinates, like
+// the game does for hazards.
+func renderTiled(id string, x, y, w, h int) {
+	texture := cachedTexture(id)
+	_, _, tileW, tileH, _ := texture.Query()
+	if tileW <= 0 || tileH <= 0 {
+		return
+	}
+	for ty := y; ty < y+h; ty += int(tileH) {
+		for tx := x; tx < x+w; tx += int(tileW) {
+			renderer.Copy(texture, nil, &sdl.Rect{int32(tx), int32(ty), tileW, tileH})
+		}
+	}
+}
+
+// renderBouncePad draws the pad's image and tints the pad over it.
+func renderBouncePad(pad LevelBouncePad, isSelected bool) {
+	if pad.Image.ID != "" {
+		texture := cachedTexture(pad.Image.ID)
+		_, _, w, h, _ := texture.Query()
+		x, y := pad.X+pad.Image.X+cameraX, pad.Y+pad.Image.Y+cameraY
+		renderer.Copy(texture, nil, &sdl.Rect{int32(x), int32(y), w, h})
+	}
+
+	var g uint8 = 128
+	if isSelected {
+		g = 255
+	}
+	renderer.SetDrawColor(255, g, 0, 150)
+	r := sdl.Rect{int32(pad.X + cameraX), int32(pad.Y + cameraY), int32(pad.W), int32(pad.H)}
+	renderer.FillRect(&r)
+}
+
+func saveCollectibles() {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(`package main
+
+type LevelCollectible struct {
+	ID   string
+	X, Y int
+}
+
+var LevelCollectibles = []LevelCollectible{` + collectiblesToString() + `}
+`)
+
+	ioutil.WriteFile("./collectibles.go", buffer.Bytes(), 0777)
+}
+
+func collectiblesToString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	n := 0
+	for _, img := range images {
+		if img.collectible {
+			buffer.WriteString(fmt.Sprintf(`
+	{"%v", %v, %v},`, img.id, img.x, img.y))
+			n++
+		}
+	}
+	if n > 0 {
+		buffer.WriteString("\n")
+	}
+
+	return string(buffer.Bytes())
+}
+
+func zoneObject(z LevelZone) LevelObject {
+	return LevelObject{z.X, z.Y, z.W, z.H, false, NoSlope, NoHazard, ""}
+}
+
+// renderZone tints a physics zone by what it does: blue for changed gravity,
+// green for a speed cap and red for wind.
+func renderZone(z LevelZone, isSelected bool) {
+	var r, g, b, a uint8 = 128, 128, 128, 60
+	if z.GravityPercent != 100 || z.MaxSpeedX != 0 || z.MaxSpeedY != 0 || z.Wind != 0 {
+		r, g, b = 0, 0, 0
+	}
+	if z.GravityPercent != 100 {
+		b = 255
+	}
+	if z.MaxSpeedX != 0 || z.MaxSpeedY != 0 {
+		g = 255
+	}
+	if z.Wind != 0 {
+		r = 255
+	}
+	if isSelected {
+		a = 120
+	}
+	renderer.SetDrawColor(r, g, b, a)
+	rect := sdl.Rect{int32(z.X + cameraX), int32(z.Y + cameraY), int32(z.W), int32(z.H)}
+	renderer.FillRect(&rect)
+}
+
+func checkpointObject(c LevelCheckpoint) LevelObject {
+	return LevelObject{c.X, c.Y, c.W, c.H, false, NoSlope, NoHazard, ""}
+}
+
+// renderCheckpoint draws a checkpoint and marks the hero's respawn point, its
+// bottom center.
+func renderCheckpoint(c LevelCheckpoint, isSelected bool) {
+	var b uint8 = 0
+	if isSelected {
+		b = 255
+	}
+	renderer.SetDrawColor(255, 255, b, 80)
+	r := sdl.Rect{int32(c.X + cameraX), int32(c.Y + cameraY), int32(c.W), int32(c.H)}
+	renderer.FillRect(&r)
+	x, y := c.X+c.W/2+cameraX, c.Y+c.H+cameraY
+	renderer.SetDrawColor(255, 255, 255, 255)
+	renderer.DrawLine(x-10, y, x+10, y)
+	renderer.DrawLine(x, y-20, x, y)
+}
+
+// platformObject is the platform at its first waypoint.
+func platformObject(p LevelPlatform) LevelObject {
+	return LevelObject{p.Path[0].X, p.Path[0].Y, p.W, p.H, p.Solid, NoSlope, NoHazard, ""}
+}
+
+// renderPlatform draws the platform at its first waypoint and its path.
+func renderPlatform(p LevelPlatform, isSelected bool) {
+	var g uint8 = 0
+	if isSelected {
+		g = 255
+	}
+	renderer.SetDrawColor(255, g, 255, 100)
+	obj := platformObject(p)
+	r := sdl.Rect{int32(obj.X + cameraX), int32(obj.Y + cameraY), int32(obj.W), int32(obj.H)}
+	renderer.FillRect(&r)
+
+	renderer.SetDrawColor(255, g, 255, 255)
+	n := len(p.Path) - 1
+	if p.Mode == Loop {
+		n++
+	}
+	for i := 0; i < n && len(p.Path) > 1; i++ {
+		a, b := p.Path[i], p.Path[(i+1)%len(p.Path)]
+		renderer.DrawLine(
+			a.X+cameraX, a.Y+cameraY,
+			b.X+cameraX, b.Y+cameraY,
+		)
+	}
+}
+
+// renderSlope draws the triangle of a sloped object as one line per column.
+func renderSlope(obj LevelObject) {
+	if obj.W <= 0 || obj.H <= 0 {
+		return
+	}
+	for x := 0; x < obj.W; x++ {
+		dx := x + 1
+		if obj.Slope == RisingLeft {
+			dx = obj.W - x
+		}
+		h := (dx*obj.H + obj.W/2) / obj.W
+		r := sdl.Rect{int32(obj.X + x), int32(obj.Y + obj.H - h), 1, int32(h)}
+		renderer.FillRect(&r)
+	}
+}
+
 func contains(obj LevelObject, x, y int) bool {
 	return x >= obj.X && y >= obj.Y && x < obj.X+obj.W && y < obj.Y+obj.H
 }
@@ -399,10 +1099,25 @@ func saveLevel() {
 var level1 = Level{
 	[]LevelObject{` + objectsToString() + `},
 	[]LevelImage{` + imagesToString() + `},
+	[]LevelPlatform{` + platformsToString() + `},
+	[]LevelTrigger{` + triggersToString() + `},
+	[]LevelCheckpoint{` + checkpointsToString() + `},
+	` + LevelRespawn.String() + `,
+	` + fmt.Sprint(LevelCharacterCollision) + `,
+	[]LevelZone{` + zonesToString() + `},
+	[]LevelBouncePad{` + bouncePadsToString() + `},
+	[]LevelCollectible{` + collectiblesToString() + `},
 }
 `)
 	ioutil.WriteFile("../level1.go", buffer.Bytes(), 0777)
 
 	saveImages()
 	saveObjects()
+	savePlatforms()
+	saveTriggers()
+	saveCheckpoints()
+	saveSettings()
+	saveZones()
+	saveBouncePads()
+	saveCollectibles()
 }
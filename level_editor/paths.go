@@ -0,0 +1,26 @@
+package main
+
+type Point struct {
+	X, Y int
+}
+
+// PathMode mirrors the game's path modes, its String method writes them into
+// the level files.
+type PathMode int
+
+const (
+	Loop PathMode = iota
+	PingPong
+	pathModeCount
+)
+
+func (m PathMode) String() string {
+	switch m {
+	case Loop:
+		return "Loop"
+	case PingPong:
+		return "PingPong"
+	default:
+		return "unknown path mode"
+	}
+}
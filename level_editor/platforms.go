@@ -0,0 +1,12 @@
+package main
+
+type LevelPlatform struct {
+	W, H  int
+	Solid bool
+	Path  []Point
+	Speed int
+	Mode  PathMode
+	Image LevelImage
+}
+
+var LevelPlatforms = []LevelPlatform{}
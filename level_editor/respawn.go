@@ -0,0 +1,22 @@
+package main
+
+// RespawnMode mirrors the game's respawn modes, its String method writes them
+// into the level files.
+type RespawnMode int
+
+const (
+	RestartRace RespawnMode = iota
+	RespawnAtCheckpoint
+	respawnModeCount
+)
+
+func (m RespawnMode) String() string {
+	switch m {
+	case RestartRace:
+		return "RestartRace"
+	case RespawnAtCheckpoint:
+		return "RespawnAtCheckpoint"
+	default:
+		return "unknown respawn mode"
+	}
+}
@@ -0,0 +1,5 @@
+package main
+
+var LevelRespawn = RestartRace
+
+var LevelCharacterCollision = false
@@ -0,0 +1,25 @@
+package main
+
+// Slope mirrors the game's slope constants, its String method writes them into
+// the level files.
+type Slope int
+
+const (
+	NoSlope Slope = iota
+	RisingRight
+	RisingLeft
+	slopeCount
+)
+
+func (s Slope) String() string {
+	switch s {
+	case NoSlope:
+		return "NoSlope"
+	case RisingRight:
+		return "RisingRight"
+	case RisingLeft:
+		return "RisingLeft"
+	default:
+		return "unknown slope"
+	}
+}
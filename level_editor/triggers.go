@@ -0,0 +1,13 @@
+package main
+
+type LevelTrigger struct {
+	X, Y, W, H int
+	Character  int
+	Event      TriggerEvent
+	Actions    []TriggerAction
+}
+
+var LevelTriggers = []LevelTrigger{
+	{9200, -1000, 1000, 350, 0, OnEnter, []TriggerAction{{Kind: HeroWins}}},
+	{9200, -1000, 1000, 350, 1, OnEnter, []TriggerAction{{Kind: BarneyWins}}},
+}
@@ -0,0 +1,20 @@
+package main
+
+// the zone values are changed by cycling through these presets, the speeds are
+// in the game's sub-pixels, 256 per pixel
+var (
+	gravityPresets  = []int{100, 50, 25, 0, 150, 200}
+	maxSpeedPresets = []int{0, 3 * 256, 6 * 256}
+	windStep        = 64
+)
+
+// nextPreset returns the preset after the current value, or the first one if
+// the value is not a preset.
+func nextPreset(presets []int, current int) int {
+	for i, p := range presets {
+		if p == current {
+			return presets[(i+1)%len(presets)]
+		}
+	}
+	return presets[0]
+}
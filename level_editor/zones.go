@@ -0,0 +1,11 @@
+package main
+
+type LevelZone struct {
+	X, Y, W, H     int
+	GravityPercent int
+	MaxSpeedX      int
+	MaxSpeedY      int
+	Wind           int
+}
+
+var LevelZones = []LevelZone{}
@@ -5,12 +5,44 @@ type LevelImage struct {
 	X, Y int
 }
 
+// LevelObject is a collision object, or a hazard if Hazard is set. Image is
+// only used for hazards, it is the ID of the image that is tiled over them.
 type LevelObject struct {
 	X, Y, W, H int
 	Solid      bool
+	Slope      Slope
+	Hazard     Hazard
+	Image      string
+}
+
+// LevelPlatform is a collision object that moves along its Path of waypoints,
+// which are positions of its top-left corner. Speed is in pixels per frame. The
+// Image is drawn relative to the platform's top-left corner.
+type LevelPlatform struct {
+	W, H  int
+	Solid bool
+	Path  []Point
+	Speed int
+	Mode  PathMode
+	Image LevelImage
 }
 
 type Level struct {
-	Objects []LevelObject
-	Images  []LevelImage
+	Objects     []LevelObject
+	Images      []LevelImage
+	Platforms   []LevelPlatform
+	Triggers    []LevelTrigger
+	Checkpoints []LevelCheckpoint
+	Respawn     RespawnMode
+	// CharacterCollision has the hero and Barney push each other and bounce
+	// off each other's heads, without it they run through each other
+	CharacterCollision bool
+	Zones              []LevelZone
+	BouncePads         []LevelBouncePad
+	Collectibles       []LevelCollectible
+}
+
+// Levels maps level IDs to their data, the IDs are stored in replay files.
+var Levels = map[string]*Level{
+	"level1": &level1,
 }
@@ -1,4 +1,5 @@
 // +build !windows sdl2
+// +build !headless
 
 package main
 
@@ -21,6 +22,9 @@ func init() {
 }
 
 func main() {
+	opts, err := parseOptions()
+	check(err)
+
 	sdl.SetHint(sdl.HINT_RENDER_VSYNC, "1")
 
 	check(sdl.Init(sdl.INIT_EVERYTHING))
@@ -44,38 +48,28 @@ func main() {
 	defer renderer.Destroy()
 	defer window.Destroy()
 	window.SetTitle("Gophette's Adventures")
-	window.SetSize(800, 600)
+	window.SetSize(opts.width, opts.height)
 	sdl.ShowCursor(0)
 
-	window.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
-	fullscreen := true
+	fullscreen := !opts.windowed
+	if fullscreen {
+		window.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
+	}
 
 	camera := newWindowCamera(window.GetSize())
 
 	assetLoader := newSDLAssetLoader(camera, renderer)
 	defer assetLoader.close()
 
-	// charIndex selects which character is being controlled by the user, for
-	// the final game this must be 0 but for creating the "AI" for Barney, set
-	// this to 1 and delete the recorded inputs so they are not applied
-	// additionally to the user controls
-
-	var charIndex int
-	const recordingAI = false // NOTE switch for development mode
-	if !recordingAI {
-		charIndex = 0
-	} else {
-		charIndex = 1
-		recordedInputs = recordedInputs[:0]
-		recordingInput = true
-	}
-
+	charIndex := opts.charIndex
 	game := NewGame(
 		assetLoader,
 		&sdlGraphics{renderer, camera},
 		camera,
 		charIndex,
+		opts.levelID,
 	)
+	check(opts.setUpGame(game, assetLoader.resources.GetByID))
 
 	musicData, found := assetLoader.resources.GetByID("music")
 	if found {
@@ -89,8 +83,7 @@ func main() {
 		}
 	}
 
-	frameTime := time.Second / 65
-	lastUpdate := time.Now().Add(-frameTime)
+	loop := newFixedStepLoop(UpdatesPerSecond, MaxCatchUpUpdates)
 
 	for game.Running() {
 		for e := sdl.PollEvent(); e != nil; e = sdl.PollEvent() {
@@ -104,6 +97,10 @@ func main() {
 						game.HandleInput(InputEvent{GoRight, true, charIndex})
 					case sdl.K_UP, sdl.K_SPACE, sdl.K_LCTRL:
 						game.HandleInput(InputEvent{Jump, true, charIndex})
+					case sdl.K_DOWN:
+						game.HandleInput(InputEvent{GoDown, true, charIndex})
+					case sdl.K_BACKSPACE:
+						game.HandleInput(InputEvent{Rewind, true, charIndex})
 					case sdl.K_ESCAPE:
 						game.HandleInput(InputEvent{QuitGame, true, charIndex})
 					}
@@ -116,6 +113,10 @@ func main() {
 					game.HandleInput(InputEvent{GoRight, false, charIndex})
 				case sdl.K_UP, sdl.K_SPACE, sdl.K_LCTRL:
 					game.HandleInput(InputEvent{Jump, false, charIndex})
+				case sdl.K_DOWN:
+					game.HandleInput(InputEvent{GoDown, false, charIndex})
+				case sdl.K_BACKSPACE:
+					game.HandleInput(InputEvent{Rewind, false, charIndex})
 				case sdl.K_F11:
 					if fullscreen {
 						window.SetFullscreen(0)
@@ -136,16 +137,11 @@ func main() {
 			}
 		}
 
-		now := time.Now()
-		dt := now.Sub(lastUpdate)
-		if dt > frameTime {
-			game.Update()
-			lastUpdate = now
-		}
+		alpha := loop.advance(time.Now(), game.Update)
 
 		check(renderer.SetDrawColor(0, 95, 83, 255))
 		check(renderer.Clear())
-		game.Render()
+		game.Render(alpha)
 		renderer.Present()
 	}
 }
@@ -169,6 +165,14 @@ func (img *textureImage) DrawAt(x, y int) {
 	check(img.renderer.Copy(img.texture, &img.source, &dest))
 }
 
+func (img *textureImage) DrawTranslucentAt(x, y int, opacity float32) {
+	// all images share the texture atlas so the alpha must be reset after
+	// drawing
+	check(img.texture.SetAlphaMod(uint8(0.5 + 255*opacity)))
+	img.DrawAt(x, y)
+	check(img.texture.SetAlphaMod(255))
+}
+
 func (img *textureImage) Size() (int, int) {
 	return int(img.source.W), int(img.source.H)
 }
@@ -307,3 +311,9 @@ func (graphics *sdlGraphics) ClearScreen(r, g, b uint8) {
 	check(graphics.renderer.SetDrawColor(r, g, b, 255))
 	graphics.renderer.Clear()
 }
+
+func (graphics *sdlGraphics) FillRect(rect Rectangle, r, g, b uint8) {
+	check(graphics.renderer.SetDrawColor(r, g, b, 255))
+	dest := sdl.Rect{int32(rect.X), int32(rect.Y), int32(rect.W), int32(rect.H)}
+	check(graphics.renderer.FillRect(&dest))
+}
@@ -0,0 +1,282 @@
+// +build headless
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gonutz/blob"
+)
+
+// the headless backend runs the game without a window, graphics or audio,
+// images and sounds only record what would have been drawn or played; this way
+// the whole race can be simulated in tests and on build machines
+
+const headlessMaxFrames = 10000
+
+func main() {
+	verify := flag.Bool("verify", false,
+		"re-simulate the -replay and compare it to its state checks")
+	opts, err := parseOptions()
+	check(err)
+
+	if *verify && opts.replay == nil {
+		check(fmt.Errorf("-verify needs a -replay file"))
+	}
+
+	assets := newHeadlessAssetLoader(resourceBlobFile)
+	camera := newWindowCamera(opts.width, opts.height)
+	game := NewGame(
+		assets,
+		&headlessGraphics{assets.log},
+		camera,
+		opts.charIndex,
+		opts.levelID,
+	)
+	var getResource func(id string) ([]byte, bool)
+	if assets.resources != nil {
+		getResource = assets.resources.GetByID
+	}
+	check(opts.setUpGame(game, getResource))
+
+	if *verify {
+		desync, err := VerifyReplay(game, opts.replay)
+		check(err)
+		if desync != nil {
+			fmt.Println(desync)
+			os.Exit(1)
+		}
+		fmt.Printf("%v state checks match\n", len(opts.replay.Checks))
+		return
+	}
+
+	var draws, sounds int
+	for assets.log.frame = 0; assets.log.frame < headlessMaxFrames; assets.log.frame++ {
+		if !game.Running() || game.state == CameraShowsBarneyWinning {
+			break
+		}
+		game.Update()
+		game.Render(1)
+		draws += len(assets.log.draws)
+		sounds += len(assets.log.sounds)
+		for _, s := range assets.log.sounds {
+			fmt.Printf("frame %v: play sound %q\n", s.Frame, s.ID)
+		}
+		assets.log.reset()
+	}
+
+	fmt.Printf(
+		"simulated %v frames (%v race frames), %v images drawn, %v sounds played\n",
+		assets.log.frame, game.frame, draws, sounds,
+	)
+	if game.state == CameraShowsBarneyWinning {
+		fmt.Println("Barney reached the goal")
+	}
+}
+
+// headlessLog records all draw and sound calls made by the game. The current
+// frame has to be set from the outside before calling Update and Render.
+type headlessLog struct {
+	frame  int
+	clears int
+	draws  []headlessDraw
+	fills  []headlessFill
+	sounds []headlessSoundPlay
+}
+
+type headlessDraw struct {
+	Frame   int
+	ID      string
+	X, Y    int
+	Opacity float32
+}
+
+type headlessFill struct {
+	Frame   int
+	Rect    Rectangle
+	R, G, B uint8
+}
+
+type headlessSoundPlay struct {
+	Frame int
+	ID    string
+}
+
+func (l *headlessLog) reset() {
+	l.clears = 0
+	l.draws = l.draws[:0]
+	l.fills = l.fills[:0]
+	l.sounds = l.sounds[:0]
+}
+
+type headlessGraphics struct {
+	log *headlessLog
+}
+
+func (g *headlessGraphics) ClearScreen(r, gr, b uint8) {
+	g.log.clears++
+}
+
+func (g *headlessGraphics) FillRect(rect Rectangle, r, gr, b uint8) {
+	g.log.fills = append(g.log.fills, headlessFill{g.log.frame, rect, r, gr, b})
+}
+
+type headlessImage struct {
+	log           *headlessLog
+	id            string
+	width, height int
+}
+
+func (img *headlessImage) DrawAt(x, y int) {
+	img.DrawTranslucentAt(x, y, 1)
+}
+
+func (img *headlessImage) DrawTranslucentAt(x, y int, opacity float32) {
+	img.log.draws = append(
+		img.log.draws,
+		headlessDraw{img.log.frame, img.id, x, y, opacity},
+	)
+}
+
+func (img *headlessImage) Size() (int, int) {
+	return img.width, img.height
+}
+
+type headlessSound struct {
+	log    *headlessLog
+	id     string
+	length time.Duration
+}
+
+func (s *headlessSound) PlayOnce() {
+	s.log.sounds = append(s.log.sounds, headlessSoundPlay{s.log.frame, s.id})
+}
+
+func (s *headlessSound) Length() time.Duration {
+	return s.length
+}
+
+// headlessAssetLoader reads the resource blob file if it exists. Without it,
+// stub assets are generated: all images have the same size and the collision
+// rectangles are made up, this means the physics will differ from the real
+// game.
+type headlessAssetLoader struct {
+	log       *headlessLog
+	resources *blob.Blob
+	images    map[string]*headlessImage
+	sounds    map[string]*headlessSound
+}
+
+const (
+	stubImageSize = 64
+	stubSoundTime = time.Second
+)
+
+var stubCollisionRect = Rectangle{10, 5, 44, 59}
+
+func newHeadlessAssetLoader(blobPath string) *headlessAssetLoader {
+	l := &headlessAssetLoader{
+		log:    &headlessLog{},
+		images: make(map[string]*headlessImage),
+		sounds: make(map[string]*headlessSound),
+	}
+	if file, err := os.Open(blobPath); err == nil {
+		defer file.Close()
+		l.resources, err = blob.Read(file)
+		check(err)
+	}
+	return l
+}
+
+func (l *headlessAssetLoader) LoadImage(id string) Image {
+	if img, ok := l.images[id]; ok {
+		return img
+	}
+	img := &headlessImage{l.log, id, stubImageSize, stubImageSize}
+	if l.resources != nil {
+		data, _ := l.resources.GetByID(id)
+		if data == nil {
+			panic("unknown image resource: " + id)
+		}
+		// the image data is the location in the texture atlas
+		var bounds rect
+		check(binary.Read(bytes.NewReader(data), binary.LittleEndian, &bounds))
+		img.width, img.height = int(bounds.W), int(bounds.H)
+	}
+	l.images[id] = img
+	return img
+}
+
+func (l *headlessAssetLoader) LoadSound(id string) Sound {
+	if sound, ok := l.sounds[id]; ok {
+		return sound
+	}
+	sound := &headlessSound{l.log, id, stubSoundTime}
+	if l.resources != nil {
+		data, _ := l.resources.GetByID(id)
+		if data == nil {
+			panic("unknown sound resource: " + id)
+		}
+		sound.length = wavLength(data)
+	}
+	l.sounds[id] = sound
+	return sound
+}
+
+func (l *headlessAssetLoader) LoadRectangle(id string) Rectangle {
+	if l.resources == nil {
+		return stubCollisionRect
+	}
+	data, found := l.resources.GetByID(id)
+	if !found {
+		panic("unknown rectangle resource: " + id)
+	}
+	var r rect
+	check(binary.Read(bytes.NewReader(data), binary.LittleEndian, &r))
+	return Rectangle{int(r.X), int(r.Y), int(r.W), int(r.H)}
+}
+
+type rect struct {
+	X, Y, W, H int32
+}
+
+// wavLength walks the RIFF chunks of a WAV file and computes the play time from
+// the byte rate in the "fmt " chunk and the size of the "data" chunk. It
+// returns 0 if the data is not a valid WAV file.
+func wavLength(data []byte) time.Duration {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0
+	}
+	var byteRate, dataSize uint32
+	for chunk := data[12:]; len(chunk) >= 8; {
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+		if id == "fmt " && len(chunk) >= 16 {
+			byteRate = binary.LittleEndian.Uint32(chunk[12:16])
+		}
+		if id == "data" {
+			dataSize = size
+		}
+		// chunks are padded to an even number of bytes
+		next := 8 + int(size) + int(size%2)
+		if next > len(chunk) {
+			break
+		}
+		chunk = chunk[next:]
+	}
+	if byteRate == 0 {
+		return 0
+	}
+	return time.Duration(dataSize) * time.Second / time.Duration(byteRate)
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,97 @@
+// +build headless
+
+package main
+
+import "testing"
+
+// newTestGame creates a headless game in level1 that does not save personal
+// bests to the user's config directory.
+func newTestGame(charIndex int) (*Game, *headlessAssetLoader) {
+	assets := newHeadlessAssetLoader(resourceBlobFile)
+	camera := newWindowCamera(800, 600)
+	game := NewGame(assets, &headlessGraphics{assets.log}, camera, charIndex, "level1")
+	game.timer.saveBests = false
+	game.collections.saveBests = false
+	return game, assets
+}
+
+// logStubAssets notes that without the resource blob the characters have
+// made-up collision rectangles, a passing route test then does not mean that
+// Barney's run works in the real game.
+func logStubAssets(t *testing.T, assets *headlessAssetLoader) {
+	t.Helper()
+	if assets.resources == nil {
+		t.Logf("%v not found, using stub assets", resourceBlobFile)
+	}
+}
+
+func TestBarneyReachesGoal(t *testing.T) {
+	game, assets := newTestGame(0)
+	logStubAssets(t, assets)
+	for assets.log.frame = 0; assets.log.frame < headlessMaxFrames; assets.log.frame++ {
+		if game.state == CameraShowsBarneyWinning {
+			return
+		}
+		game.Update()
+		game.Render(1)
+		assets.log.reset()
+	}
+	t.Fatalf("Barney did not reach the goal in %v frames", headlessMaxFrames)
+}
+
+func TestBarneyRunsAgainAfterRespawn(t *testing.T) {
+	// Barney's positions in every race frame of his recorded run
+	var run []Rectangle
+	game, assets := newTestGame(0)
+	logStubAssets(t, assets)
+	for frame := 0; frame < headlessMaxFrames && game.state != CameraShowsBarneyWinning; frame++ {
+		if game.state == Playing {
+			run = append(run, game.characters[1].Position)
+		}
+		game.Update()
+	}
+
+	const respawnFrame, checkedFrames = 300, 200
+	if len(run) < checkedFrames {
+		t.Fatalf("Barney's run only has %v race frames", len(run))
+	}
+	game, _ = newTestGame(0)
+	for game.state != Playing || game.frame < respawnFrame {
+		game.Update()
+	}
+	game.hazards = []hazardObject{{Bounds: game.characters[1].Position, Kind: Spikes}}
+	game.Update()
+	game.hazards = nil
+	for i := 0; i < checkedFrames; i++ {
+		if pos := game.characters[1].Position; pos != run[i] {
+			t.Fatalf("%v frames after respawning Barney is at %v instead of %v", i, pos, run[i])
+		}
+		game.Update()
+	}
+}
+
+func TestHazardImageIsTiledOverBounds(t *testing.T) {
+	game, assets := newTestGame(0)
+	spikes := &headlessImage{assets.log, "spikes", 32, 32}
+	game.hazards = []hazardObject{
+		{Bounds: Rectangle{100, 200, 150, 20}, Kind: Spikes, image: spikes},
+		{Bounds: Rectangle{500, 200, 100, 100}, Kind: Water},
+	}
+	assets.log.reset()
+	game.renderHazards()
+	want := []headlessDraw{
+		{0, "spikes", 100, 200, 1},
+		{0, "spikes", 132, 200, 1},
+		{0, "spikes", 164, 200, 1},
+		{0, "spikes", 196, 200, 1},
+		{0, "spikes", 228, 200, 1},
+	}
+	if len(assets.log.draws) != len(want) {
+		t.Fatalf("want %v draws but have %v", want, assets.log.draws)
+	}
+	for i := range want {
+		if assets.log.draws[i] != want[i] {
+			t.Errorf("draw %v is %v instead of %v", i, assets.log.draws[i], want[i])
+		}
+	}
+}
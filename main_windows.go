@@ -1,4 +1,4 @@
-// +build !sdl2
+// +build !sdl2,!headless
 
 package main
 
@@ -29,6 +29,7 @@ func init() {
 var (
 	windowW           = 800
 	windowH           = 800
+	windowed          bool
 	game              *Game
 	camera            *windowCamera
 	charIndex         int
@@ -98,6 +99,10 @@ func handleEvent(window w32.HWND, message uint32, w, l uintptr) uintptr {
 				game.HandleInput(InputEvent{GoRight, true, charIndex})
 			case w32.VK_UP, w32.VK_SPACE:
 				game.HandleInput(InputEvent{Jump, true, charIndex})
+			case w32.VK_DOWN:
+				game.HandleInput(InputEvent{GoDown, true, charIndex})
+			case w32.VK_BACK:
+				game.HandleInput(InputEvent{Rewind, true, charIndex})
 			case w32.VK_ESCAPE:
 				game.HandleInput(InputEvent{QuitGame, true, charIndex})
 			}
@@ -111,6 +116,10 @@ func handleEvent(window w32.HWND, message uint32, w, l uintptr) uintptr {
 			game.HandleInput(InputEvent{GoRight, false, charIndex})
 		case w32.VK_UP, w32.VK_SPACE:
 			game.HandleInput(InputEvent{Jump, false, charIndex})
+		case w32.VK_DOWN:
+			game.HandleInput(InputEvent{GoDown, false, charIndex})
+		case w32.VK_BACK:
+			game.HandleInput(InputEvent{Rewind, false, charIndex})
 		case w32.VK_F11:
 			toggleFullscreen(window)
 		case w32.VK_ESCAPE:
@@ -133,6 +142,10 @@ func handleEvent(window w32.HWND, message uint32, w, l uintptr) uintptr {
 }
 
 func main() {
+	opts, err := parseOptions()
+	check(err)
+	windowW, windowH, windowed = opts.width, opts.height, opts.windowed
+
 	windowHandle, err := openWindow("class name", handleEvent, 0, 0, windowW, windowH)
 	check(err)
 	window := w32.HWND(windowHandle)
@@ -196,26 +209,15 @@ func main() {
 	assetLoader := newWindowsAssetLoader(device, graphics, camera)
 	defer assetLoader.close()
 
-	// charIndex selects which character is being controlled by the user, for
-	// the final game this must be 0 but for creating the "AI" for Barney, set
-	// this to 1 and delete the recorded inputs so they are not applied
-	// additionally to the user controls
-
-	const recordingAI = false // NOTE switch for development mode
-	if !recordingAI {
-		charIndex = 0
-	} else {
-		charIndex = 1
-		recordedInputs = recordedInputs[:0]
-		recordingInput = true
-	}
-
+	charIndex = opts.charIndex
 	game = NewGame(
 		assetLoader,
 		graphics,
 		camera,
 		charIndex,
+		opts.levelID,
 	)
+	check(opts.setUpGame(game, assetLoader.resources.GetByID))
 
 	music := assetLoader.LoadSound("music_wav")
 	go func() {
@@ -225,10 +227,11 @@ func main() {
 		}
 	}()
 
-	toggleFullscreen(window)
+	if !windowed {
+		toggleFullscreen(window)
+	}
 
-	frameTime := time.Second / 65
-	lastUpdate := time.Now().Add(-frameTime)
+	loop := newFixedStepLoop(UpdatesPerSecond, MaxCatchUpUpdates)
 
 	var msg w32.MSG
 	w32.PeekMessage(&msg, 0, 0, 0, w32.PM_NOREMOVE)
@@ -237,12 +240,7 @@ func main() {
 			w32.TranslateMessage(&msg)
 			w32.DispatchMessage(&msg)
 		} else {
-			now := time.Now()
-			dt := now.Sub(lastUpdate)
-			if dt > frameTime {
-				game.Update()
-				lastUpdate = now
-			}
+			alpha := loop.advance(time.Now(), game.Update)
 
 			check(device.SetViewport(
 				d3d9.VIEWPORT{0, 0, uint32(windowW), uint32(windowH), 0, 1},
@@ -254,7 +252,7 @@ func main() {
 				1,
 				0,
 			))
-			game.Render()
+			game.Render(alpha)
 			graphics.flush()
 			check(device.Present(
 				&d3d9.RECT{0, 0, int32(windowW), int32(windowH)},
@@ -333,7 +331,11 @@ type textureImage struct {
 func (img *textureImage) DrawAt(x, y int) {
 	// this call is referred to the graphics which will accumulate all calls
 	// and then flush them out in one go at rendering time
-	img.graphics.drawImageAt(img, x, y)
+	img.graphics.drawImageAt(img, x, y, 1)
+}
+
+func (img *textureImage) DrawTranslucentAt(x, y int, opacity float32) {
+	img.graphics.drawImageAt(img, x, y, opacity)
 }
 
 func (img *textureImage) Size() (int, int) {
@@ -495,12 +497,18 @@ type windowsGraphics struct {
 	vertices                 []float32
 	textureCoords            []float32
 	vertexDecl               *d3d9.VertexDeclaration
+	// opacity is the pixel shader's opacity for all accumulated images
+	opacity float32
+	// filled rectangles are drawn after the images, see flush
+	rects      []d3d9.RECT
+	rectColors []d3d9.COLOR
 }
 
 func newWindowsGraphics(device *d3d9.Device, camera *windowCamera) *windowsGraphics {
 	g := &windowsGraphics{
-		device: device,
-		camera: camera,
+		device:  device,
+		camera:  camera,
+		opacity: 1,
 	}
 	check(g.init())
 	return g
@@ -547,7 +555,24 @@ func (graphics *windowsGraphics) ClearScreen(r, g, b uint8) {
 	))
 }
 
-func (g *windowsGraphics) drawImageAt(img *textureImage, x, y int) {
+func (graphics *windowsGraphics) FillRect(rect Rectangle, r, g, b uint8) {
+	graphics.rects = append(graphics.rects, d3d9.RECT{
+		int32(rect.X),
+		int32(rect.Y),
+		int32(rect.X + rect.W),
+		int32(rect.Y + rect.H),
+	})
+	graphics.rectColors = append(graphics.rectColors, d3d9.ColorRGB(r, g, b))
+}
+
+func (g *windowsGraphics) drawImageAt(img *textureImage, x, y int, opacity float32) {
+	// the opacity is a shader constant so images with different opacities
+	// can not be drawn in one go
+	if opacity != g.opacity {
+		g.flushImages()
+		g.opacity = opacity
+	}
+
 	dx, dy := g.camera.offset()
 	x += dx
 	y += dy
@@ -577,6 +602,24 @@ func (g *windowsGraphics) drawImageAt(img *textureImage, x, y int) {
 }
 
 func (g *windowsGraphics) flush() {
+	g.flushImages()
+
+	// the rectangles are simply cleared with their color, this needs no
+	// shader but it also means they are always on top of the images
+	for i := range g.rects {
+		check(g.device.Clear(
+			g.rects[i:i+1],
+			d3d9.CLEAR_TARGET,
+			g.rectColors[i],
+			1,
+			0,
+		))
+	}
+	g.rects = g.rects[:0]
+	g.rectColors = g.rectColors[:0]
+}
+
+func (g *windowsGraphics) flushImages() {
 	if len(g.vertices) == 0 {
 		// nothing to do in this case
 		return
@@ -637,6 +680,7 @@ func (g *windowsGraphics) flush() {
 		1,
 	).Transposed()
 	check(g.device.SetVertexShaderConstantF(0, mvp[:]))
+	check(g.device.SetPixelShaderConstantF(0, []float32{g.opacity, 0, 0, 0}))
 
 	check(g.device.BeginScene())
 	check(g.device.DrawPrimitive(d3d9.PT_TRIANGLELIST, 0, uint(len(g.vertices)/3)))
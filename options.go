@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// options are the command line options, they are shared by all backends.
+type options struct {
+	recordAI      bool
+	hashInterval  int
+	replayFile    string
+	windowed      bool
+	width, height int
+	levelID       string
+	// charIndex selects which character is being controlled by the user
+	charIndex int
+
+	// replay is loaded from replayFile while parsing the options
+	replay *Replay
+}
+
+func parseOptions() (options, error) {
+	opts := options{
+		width:   800,
+		height:  600,
+		levelID: "level1",
+	}
+
+	flag.BoolVar(&opts.recordAI, "record-ai", false,
+		"play as Barney and record the run to "+recordedReplayFile)
+	flag.IntVar(&opts.hashInterval, "hash-interval", 0,
+		"when recording, store a state check every `n` frames")
+	flag.StringVar(&opts.replayFile, "replay", "",
+		"watch the run saved in the given replay `file`")
+	flag.BoolVar(&opts.windowed, "windowed", false,
+		"start in a window instead of full-screen")
+	flag.Var(sizeFlag{&opts.width, &opts.height}, "size",
+		"window size as `WxH`, e.g. 1024x768")
+	flag.StringVar(&opts.levelID, "level", opts.levelID,
+		"the `name` of the level to play")
+	flag.Var((*characterFlag)(&opts.charIndex), "character",
+		"the character to control, gophette or barney")
+	flag.Parse()
+
+	characterSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "character" {
+			characterSet = true
+		}
+	})
+
+	if flag.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %v", flag.Args())
+	}
+
+	if opts.recordAI {
+		if characterSet && opts.charIndex != 1 {
+			return opts, errors.New("-record-ai always plays as barney, use -character barney or leave it out")
+		}
+		opts.charIndex = 1
+	}
+
+	if opts.hashInterval < 0 {
+		return opts, errors.New("-hash-interval must not be negative")
+	}
+
+	if opts.replayFile != "" {
+		if opts.recordAI {
+			return opts, errors.New("-record-ai and -replay can not be combined")
+		}
+		replay, err := LoadReplayFile(opts.replayFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.replay = replay
+		if replay.LevelID != "" {
+			opts.levelID = replay.LevelID
+		}
+	}
+
+	if _, ok := Levels[opts.levelID]; !ok {
+		return opts, fmt.Errorf("unknown level: %q", opts.levelID)
+	}
+
+	return opts, nil
+}
+
+// setUpGame applies the options to a newly created game. getResource is used
+// to look for Barney's run in the embedded resources, it may be nil.
+func (opts *options) setUpGame(
+	game *Game,
+	getResource func(id string) ([]byte, bool),
+) error {
+	barneyReplay, err := loadBarneyReplay(getResource)
+	if err != nil {
+		return err
+	}
+	game.SetAIReplay(barneyReplay)
+
+	if opts.charIndex == 1 {
+		// do not apply Barney's old run in addition to the user controls
+		game.SetAIInputs(nil)
+	}
+
+	if opts.recordAI {
+		game.Recorder().Start(opts.charIndex)
+		game.Recorder().SetHashInterval(opts.hashInterval)
+	}
+
+	if opts.replay != nil {
+		game.WatchReplay(opts.replay)
+	}
+
+	return nil
+}
+
+type sizeFlag struct {
+	width, height *int
+}
+
+func (f sizeFlag) String() string {
+	if f.width == nil || f.height == nil {
+		return ""
+	}
+	return fmt.Sprintf("%vx%v", *f.width, *f.height)
+}
+
+func (f sizeFlag) Set(s string) error {
+	parts := strings.Split(strings.ToLower(s), "x")
+	if len(parts) != 2 {
+		return errors.New("size must be given as WxH")
+	}
+	w, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return err
+	}
+	h, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return err
+	}
+	if w <= 0 || h <= 0 {
+		return errors.New("size must be positive")
+	}
+	*f.width, *f.height = w, h
+	return nil
+}
+
+type characterFlag int
+
+var characterNames = []string{"gophette", "barney"}
+
+func (f *characterFlag) String() string {
+	if f == nil || int(*f) >= len(characterNames) {
+		return ""
+	}
+	return characterNames[*f]
+}
+
+func (f *characterFlag) Set(s string) error {
+	for i, name := range characterNames {
+		if strings.ToLower(s) == name {
+			*f = characterFlag(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown character %q, use one of %v", s, characterNames)
+}
@@ -0,0 +1,167 @@
+package main
+
+import "math"
+
+// PathMode says what a moving platform does at the end of its path.
+type PathMode int
+
+const (
+	// Loop goes from the last waypoint straight back to the first one
+	Loop PathMode = iota
+	// PingPong goes back along the path in reverse
+	PingPong
+)
+
+// String returns the constant's name, the level editor writes these into the
+// level files.
+func (m PathMode) String() string {
+	switch m {
+	case Loop:
+		return "Loop"
+	case PingPong:
+		return "PingPong"
+	default:
+		return "unknown path mode"
+	}
+}
+
+// movingPlatform is a collision object that moves along a path of waypoints.
+// Its position only depends on the frame number so replays stay in sync.
+type movingPlatform struct {
+	// object is the index of the platform in Game.objects
+	object int
+	path   []Point
+	// lengths[i] is the length of the segment from waypoint i to the next one
+	lengths []int
+	total   int
+	speed   int
+	mode    PathMode
+
+	image       Image
+	imageOffset Point
+	// lastBounds are the bounds before the last update, for rendering
+	lastBounds Rectangle
+}
+
+func newMovingPlatform(object int, p *LevelPlatform) movingPlatform {
+	m := movingPlatform{
+		object:      object,
+		path:        p.Path,
+		speed:       p.Speed,
+		mode:        p.Mode,
+		imageOffset: Point{p.Image.X, p.Image.Y},
+	}
+	n := len(p.Path) - 1
+	if p.Mode == Loop {
+		n++
+	}
+	for i := 0; i < n; i++ {
+		a, b := p.Path[i], p.Path[(i+1)%len(p.Path)]
+		dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+		length := int(math.Sqrt(dx*dx+dy*dy) + 0.5)
+		m.lengths = append(m.lengths, length)
+		m.total += length
+	}
+	if p.Mode == PingPong {
+		m.total *= 2
+	}
+	return m
+}
+
+// positionAt returns the platform's top-left corner at the given frame.
+func (m *movingPlatform) positionAt(frame int) Point {
+	if m.total == 0 || m.speed == 0 {
+		return m.path[0]
+	}
+	dist := (frame * m.speed) % m.total
+	if m.mode == PingPong && dist > m.total/2 {
+		dist = m.total - dist
+	}
+	for i, length := range m.lengths {
+		if dist <= length {
+			a, b := m.path[i], m.path[(i+1)%len(m.path)]
+			if length == 0 {
+				return a
+			}
+			return Point{
+				a.X + (b.X-a.X)*dist/length,
+				a.Y + (b.Y-a.Y)*dist/length,
+			}
+		}
+		dist -= length
+	}
+	return m.path[0]
+}
+
+// placePlatforms puts all platforms where they belong at the current frame
+// without carrying any characters, this is a teleport.
+func (g *Game) placePlatforms() {
+	for i := range g.platforms {
+		p := &g.platforms[i]
+		pos := p.positionAt(g.frame)
+		obj := &g.objects[p.object]
+		obj.Bounds = obj.Bounds.MoveTo(pos.X, pos.Y)
+		p.lastBounds = obj.Bounds
+	}
+}
+
+// movePlatforms moves all platforms to their position at the current frame and
+// carries along all characters that stand on them.
+func (g *Game) movePlatforms() {
+	chars := []*Character{g.characters[0], g.characters[1], g.ghost.char}
+	for i := range g.platforms {
+		p := &g.platforms[i]
+		obj := &g.objects[p.object]
+		old := obj.Bounds
+		p.lastBounds = old
+		pos := p.positionAt(g.frame)
+		dx, dy := pos.X-old.X, pos.Y-old.Y
+
+		var carried []*Character
+		for _, char := range chars {
+			if standsOn(char, old) {
+				carried = append(carried, char)
+			}
+		}
+
+		// the platform must not be in the way of the characters it carries:
+		// when going up it is below their move space at its old position,
+		// when going down it is below it at its new position
+		if dy >= 0 {
+			obj.Bounds = obj.Bounds.MoveTo(pos.X, pos.Y)
+		}
+		for _, char := range carried {
+			char.Position, _ = g.MoveInY(char.Position, dy)
+			char.Position, _ = g.MoveInX(char.Position, dx)
+		}
+		obj.Bounds = obj.Bounds.MoveTo(pos.X, pos.Y)
+	}
+}
+
+func standsOn(char *Character, bounds Rectangle) bool {
+	pos := char.Position
+	return !char.InAir && pos.Y+pos.H == bounds.Y &&
+		pos.X < bounds.X+bounds.W && pos.X+pos.W > bounds.X
+}
+
+func (g *Game) renderPlatforms(alpha float64) {
+	for i := range g.platforms {
+		p := &g.platforms[i]
+		if p.image != nil {
+			pos := p.lastBounds.Interpolate(g.objects[p.object].Bounds, alpha)
+			p.image.DrawAt(pos.X+p.imageOffset.X, pos.Y+p.imageOffset.Y)
+		}
+	}
+}
+
+// nearObjects returns the indices of the objects that might overlap r, that is
+// the static objects near r followed by all moving platforms.
+func (g *Game) nearObjects(r Rectangle) []int {
+	near := g.grid.near(r)
+	for i := range g.platforms {
+		near = append(near, g.platforms[i].object)
+	}
+	// keep the grown buffer for the next query
+	g.grid.found = near
+	return near
+}
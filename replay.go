@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// A replay file starts with replayMagic, followed by the format version as a
+// little endian uint32, followed by the gob encoded Replay.
+const (
+	replayMagic   = "GOPHETTE REPLAY\n"
+	ReplayVersion = 5
+)
+
+const (
+	// recordedReplayFile is where a recording is saved when quitting the game
+	recordedReplayFile = "./recorded.replay"
+	// barneyReplayFile, if it exists, overrides Barney's embedded run
+	barneyReplayFile = "./barney.replay"
+	// barneyReplayID is the resource ID of Barney's run in the resource blob
+	barneyReplayID = "barney replay"
+)
+
+// Replay is a recorded run that can be stored in a file and played back.
+type Replay struct {
+	Version    int
+	LevelID    string
+	Date       time.Time
+	Result     ReplayResult
+	FrameCount int
+	Characters []ReplayCharacter
+	Inputs     []inputRecord
+	// HashInterval is the number of frames between state checks, it is 0 if
+	// there are no Checks; this was added in version 2
+	HashInterval int
+	Checks       []StateCheck
+}
+
+// ReplayCharacter describes a character whose inputs are part of the replay.
+// The params are stored so the run plays back the same even if the default
+// params change.
+type ReplayCharacter struct {
+	Index  int
+	Params CharacterParams
+}
+
+type ReplayResult int
+
+const (
+	Unfinished ReplayResult = iota
+	HeroWon
+	BarneyWon
+)
+
+func (r ReplayResult) String() string {
+	switch r {
+	case Unfinished:
+		return "Unfinished"
+	case HeroWon:
+		return "HeroWon"
+	case BarneyWon:
+		return "BarneyWon"
+	default:
+		return "unknown result"
+	}
+}
+
+func (r *Replay) Write(w io.Writer) error {
+	r.Version = ReplayVersion
+	if _, err := io.WriteString(w, replayMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(r.Version)); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(r)
+}
+
+func ReadReplay(r io.Reader) (*Replay, error) {
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, err
+	}
+	if version > ReplayVersion {
+		return nil, fmt.Errorf(
+			"replay version %v is newer than the supported version %v",
+			version, ReplayVersion,
+		)
+	}
+	var replay Replay
+	if err := gob.NewDecoder(r).Decode(&replay); err != nil {
+		return nil, err
+	}
+	if version < 3 {
+		replay.upgradeToSubPixels()
+	}
+	if version < 4 {
+		// there were no wall abilities
+		replay.upgradeUnrecordedParams(func(p *CharacterParams, defaults CharacterParams) {
+			p.WallSlideSpeedY = defaults.WallSlideSpeedY
+			p.WallJumpSpeedX = defaults.WallJumpSpeedX
+			p.WallJumpSpeedY = defaults.WallJumpSpeedY
+		})
+	}
+	if version < 5 {
+		// there was no coyote time and no jump buffering
+		replay.upgradeUnrecordedParams(func(p *CharacterParams, defaults CharacterParams) {
+			p.CoyoteFrames = defaults.CoyoteFrames
+			p.JumpBufferFrames = defaults.JumpBufferFrames
+		})
+	}
+	if version < ReplayVersion {
+		// the state has new fields since then
+		replay.rehashChecks()
+	}
+	return &replay, nil
+}
+
+// upgradeToSubPixels converts a replay from before version 3, where speeds and
+// params were in whole pixels.
+func (r *Replay) upgradeToSubPixels() {
+	for i := range r.Characters {
+		r.Characters[i].Params = r.Characters[i].Params.pixelsToSubPixels()
+	}
+	for i := range r.Checks {
+		state := &r.Checks[i].State
+		for j := range state.Characters {
+			c := &state.Characters[j]
+			c.SpeedX *= SubPixels
+			c.SpeedY *= SubPixels
+			c.Params = c.Params.pixelsToSubPixels()
+		}
+	}
+}
+
+// upgradeUnrecordedParams calls upgrade for the params in the state checks of
+// the characters that are not in the replay. New params are 0 in older replays,
+// the characters in the replay keep playing without them but the others play
+// with the default params, which have them now.
+func (r *Replay) upgradeUnrecordedParams(upgrade func(p *CharacterParams, defaults CharacterParams)) {
+	defaults := [2]CharacterParams{HeroParams, BarneyParams}
+	recorded := make(map[int]bool)
+	for _, char := range r.Characters {
+		recorded[char.Index] = true
+	}
+	for i := range r.Checks {
+		state := &r.Checks[i].State
+		for j := range state.Characters {
+			if !recorded[j] {
+				upgrade(&state.Characters[j].Params, defaults[j])
+			}
+		}
+	}
+}
+
+// rehashChecks computes the hashes of the state checks again, the states of
+// older replays are missing the fields that were added since then so their
+// hashes differ.
+func (r *Replay) rehashChecks() {
+	for i := range r.Checks {
+		r.Checks[i].Hash = r.Checks[i].State.Hash()
+	}
+}
+
+func LoadReplayFile(path string) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadReplay(bufio.NewReader(file))
+}
+
+func SaveReplayFile(path string, replay *Replay) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	if err := replay.Write(w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// loadBarneyReplay looks for Barney's run in the barneyReplayFile first, then
+// in the resources under barneyReplayID. If neither exists, the run that is
+// compiled into the game is used.
+func loadBarneyReplay(getResource func(id string) ([]byte, bool)) (*Replay, error) {
+	if _, err := os.Stat(barneyReplayFile); err == nil {
+		return LoadReplayFile(barneyReplayFile)
+	}
+	if getResource != nil {
+		if data, found := getResource(barneyReplayID); found {
+			return ReadReplay(bytes.NewReader(data))
+		}
+	}
+	return builtInBarneyReplay(), nil
+}
+
+func builtInBarneyReplay() *Replay {
+	return &Replay{
+		Version:    ReplayVersion,
+		LevelID:    "level1",
+		Result:     BarneyWon,
+		FrameCount: recordedInputs[len(recordedInputs)-1].Frame,
+		Characters: []ReplayCharacter{{1, BarneyParams}},
+		Inputs:     recordedInputs,
+	}
+}
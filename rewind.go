@@ -0,0 +1,100 @@
+package main
+
+// RewindFrames is how far back in time the game can be rewound.
+const RewindFrames = 10 * UpdatesPerSecond
+
+// rewindBuffer is a ring buffer of the most recent snapshots, when it is full
+// pushing a new snapshot drops the oldest one. The snapshots are overwritten in
+// place, their lists are reused and they share the recorded inputs with the
+// game, so a push does not allocate.
+type rewindBuffer struct {
+	snapshots []Snapshot
+	// first is the index of the oldest snapshot, count the number of valid
+	// snapshots starting at first
+	first int
+	count int
+}
+
+func newRewindBuffer(capacity int) *rewindBuffer {
+	return &rewindBuffer{snapshots: make([]Snapshot, capacity)}
+}
+
+// push takes a snapshot of the game.
+func (b *rewindBuffer) push(g *Game) {
+	if b.count < len(b.snapshots) {
+		g.snapshotTo(&b.snapshots[(b.first+b.count)%len(b.snapshots)])
+		b.count++
+	} else {
+		g.snapshotTo(&b.snapshots[b.first])
+		b.first = (b.first + 1) % len(b.snapshots)
+	}
+}
+
+// pop removes and returns the most recent snapshot, it is only valid until the
+// next push.
+func (b *rewindBuffer) pop() (*Snapshot, bool) {
+	if b.count == 0 {
+		return nil, false
+	}
+	b.count--
+	return &b.snapshots[(b.first+b.count)%len(b.snapshots)], true
+}
+
+// startRewinding is called when the rewind key is pressed.
+func (g *Game) startRewinding() {
+	g.rewinding = true
+	// the most recent snapshot is the current state, drop it so the first
+	// step actually goes back in time
+	if g.rewind.count > 1 {
+		g.rewind.pop()
+	}
+}
+
+// rewindStep restores the last snapshot, this is what Update does instead of
+// simulating while the rewind key is held.
+func (g *Game) rewindStep() {
+	if s, ok := g.rewind.pop(); ok {
+		// interpolate from the current to the restored positions when
+		// rendering, i.e. backwards in time
+		last := [3]Rectangle{
+			g.characters[0].Position,
+			g.characters[1].Position,
+			g.ghost.char.Position,
+		}
+		// the snapshots were taken in this level, they always fit
+		check(g.Restore(s))
+		g.characters[0].lastPosition = last[0]
+		g.characters[1].lastPosition = last[1]
+		g.ghost.char.lastPosition = last[2]
+		// keep the oldest snapshot around so we do not lose it if the rewind
+		// key is held longer than there is history, it is still in its place
+		if g.rewind.count == 0 {
+			g.rewind.count = 1
+		}
+	}
+}
+
+// stopRewinding is called when the rewind key is released. The restored input
+// state is brought in line with the keys that the user is holding right now.
+// This goes through the regular input handling so the recorders see the same
+// changes that the simulation sees.
+func (g *Game) stopRewinding() {
+	g.rewinding = false
+	if g.replayPlayer != nil {
+		return
+	}
+	index := g.primaryCharIndex
+	restored, held := g.inputStates[index], g.heldKeys
+	if restored.leftDown != held.leftDown {
+		g.handleInput(InputEvent{GoLeft, held.leftDown, index})
+	}
+	if restored.rightDown != held.rightDown {
+		g.handleInput(InputEvent{GoRight, held.rightDown, index})
+	}
+	if restored.jumpDown != held.jumpDown {
+		g.handleInput(InputEvent{Jump, held.jumpDown, index})
+	}
+	if restored.downDown != held.downDown {
+		g.handleInput(InputEvent{GoDown, held.downDown, index})
+	}
+}
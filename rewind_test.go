@@ -0,0 +1,45 @@
+// +build headless
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewindPushDoesNotAllocate(t *testing.T) {
+	game, _ := newTestGame(0)
+	game.recorder.Start(0)
+	game.recorder.SetHashInterval(1)
+	for i := 0; i < RewindFrames+1000; i++ {
+		game.Update()
+	}
+	if allocs := testing.AllocsPerRun(100, func() { game.rewind.push(game) }); allocs != 0 {
+		t.Errorf("a push allocates %v times", allocs)
+	}
+}
+
+func TestRewindGoesBackInTime(t *testing.T) {
+	// rewinding sets the last positions to interpolate backwards in time
+	state := func(g *Game) SimState {
+		s := g.simState()
+		for i := range s.Characters {
+			s.Characters[i].LastPosition = Rectangle{}
+		}
+		return s
+	}
+	game, _ := newTestGame(0)
+	var states []SimState
+	for i := 0; i < RewindFrames+1000; i++ {
+		game.Update()
+		states = append(states, state(game))
+	}
+	game.startRewinding()
+	for back := 1; back < 100; back++ {
+		game.Update()
+		want := states[len(states)-1-back]
+		if have := state(game); !reflect.DeepEqual(have, want) {
+			t.Fatalf("rewinding %v frames:\n%v", back, have.Diff(want))
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gonutz/xcf"
 	"github.com/nfnt/resize"
 	"image"
+	"image/color"
 	"image/png"
 	"io/ioutil"
 	"os"
@@ -79,6 +80,16 @@ func main() {
 		addImage(smallRight, "barney_right_"+layer)
 	}
 
+	// the wall frames are indexed by the side of the wall, she looks away
+	// from it; until there are drawn wall layers they are the jump frames
+	{
+		small := scaleImage(gophette.GetLayerByName("jump"))
+		addImage(small, "gophette_right_wall")
+		addImage(imaging.FlipH(small), "gophette_left_wall")
+		addImage(scaleImage(barney.GetLayerByName("left_jump")), "barney_right_wall")
+		addImage(scaleImage(barney.GetLayerByName("right_jump")), "barney_left_wall")
+	}
+
 	grass, err := xcf.LoadFromFile("./grass.xcf")
 	check(err)
 	for _, layer := range []string{
@@ -152,6 +163,12 @@ func main() {
 	addImage(scaleImageToFactor(intro.GetLayerByName("pc 2"), 0.67), "intro pc 2")
 	addImage(scaleImageToFactor(intro.GetLayerByName("gophette"), 0.67), "intro gophette")
 
+	// the hazard tiles are simple enough to be drawn here, the level editor
+	// sets their IDs as the hazards' images
+	addImage(spikesTile(), "spikes")
+	addImage(thornsTile(), "thorns")
+	addImage(waterTile(), "water")
+
 	{
 		music, err := ioutil.ReadFile("./background_music.ogg")
 		check(err)
@@ -172,12 +189,20 @@ func main() {
 		"barney intro text",
 		"whistle",
 		"instructions",
+		"bounce",
+		"pickup",
 	} {
 		data, err := ioutil.ReadFile(sound + ".wav")
 		check(err)
 		resources.Append(sound, data)
 	}
 
+	// Barney's run is optional, without it the game uses the run that is
+	// compiled into it
+	if replay, err := ioutil.ReadFile("./barney.replay"); err == nil {
+		resources.Append("barney replay", replay)
+	}
+
 	resources.Append("atlas", imageToBytes(textureAtlas))
 	for _, sub := range textureAtlas.SubImages {
 		resources.Append(
@@ -235,6 +260,67 @@ func scaleImageToFactor(img image.Image, f float64) image.Image {
 	)
 }
 
+const hazardTileSize = 32
+
+// spikesTile is a row of gray spikes that point up, with a dark base.
+func spikesTile() image.Image {
+	const n = hazardTileSize
+	img := image.NewNRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			// two spikes per tile, each n/2 wide at the bottom
+			dx := x%(n/2) - n/4
+			if dx < 0 {
+				dx = -dx
+			}
+			if y >= n-4 {
+				img.Set(x, y, color.NRGBA{70, 70, 80, 255})
+			} else if dx*4 <= y {
+				img.Set(x, y, color.NRGBA{uint8(150 + 2*y), uint8(150 + 2*y), 170, 255})
+			}
+		}
+	}
+	return img
+}
+
+// thornsTile is a dark green vine with thorns sticking out of it.
+func thornsTile() image.Image {
+	const n = hazardTileSize
+	img := image.NewNRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			// the vine zig-zags diagonally through the tile
+			d := (x + y) % n
+			if d < 6 || d > n-6 {
+				img.Set(x, y, color.NRGBA{30, 100, 20, 255})
+			} else if (x-y+n)%(n/2) < 2 && (d < 11 || d > n-11) {
+				img.Set(x, y, color.NRGBA{200, 200, 140, 255})
+			}
+		}
+	}
+	return img
+}
+
+// waterTile is translucent blue with a lighter wave at the top.
+func waterTile() image.Image {
+	const n = hazardTileSize
+	img := image.NewNRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			c := color.NRGBA{30, 90, 200, 160}
+			wave := x % (n / 2)
+			if wave > n/4 {
+				wave = n/2 - wave
+			}
+			if y <= wave/2+1 {
+				c = color.NRGBA{140, 190, 255, 200}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
 func check(err error) {
 	if err != nil {
 		panic(err)
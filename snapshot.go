@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Snapshot is a copy of the complete simulation state of a Game. Restoring it
+// puts the game back into the exact state it had when the snapshot was taken,
+// so updating it with the same inputs produces the same results again. The
+// level, the assets and Barney's recorded run are not part of the snapshot.
+type Snapshot struct {
+	State                GameState
+	PrePlayCountDown     int
+	PlayerDyingCountDown int
+	LosingSoundCountDown int
+	BarneyWinCountDown   int
+	PlayerWinCountDown   int
+	IntroCountUp         int
+	CurrentIntroPCImage  int
+	IntroBarneyTalking   bool
+	Frame                int
+	CameraTarget         int
+	CameraBounds         Rectangle
+	Checkpoint           Point
+	Hint                 string
+	HintCountDown        int
+	// TriggersInside tells for every trigger if its character is inside
+	TriggersInside []bool
+	// Collected tells for every collectible if the hero has it
+	Collected []bool
+
+	Characters  []CharacterSnapshot
+	InputStates []InputStateSnapshot
+
+	// the cursors are the indices of the next inputs to play, ReplayCursor is
+	// only used while watching a replay; Barney's inputs are played relative
+	// to AIStartFrame
+	AICursor     int
+	ReplayCursor int
+	AIStartFrame int
+
+	Recorder RecorderSnapshot
+	Ghost    GhostSnapshot
+	Timer    TimerSnapshot
+}
+
+type CharacterSnapshot struct {
+	Direction     int
+	Position      Rectangle
+	LastPosition  Rectangle
+	SpeedX        int
+	SpeedY        int
+	SubX          int
+	SubY          int
+	InAir         bool
+	WallSliding   bool
+	CoyoteFrames  int
+	JumpBuffer    int
+	RunFrameIndex int
+	NextRunFrame  int
+	Params        CharacterParams
+	DropThrough   []int
+	DropFrames    int
+}
+
+type InputStateSnapshot struct {
+	LeftDown          bool
+	RightDown         bool
+	JumpDown          bool
+	MustJumpThisFrame bool
+	DownDown          bool
+}
+
+// RecorderSnapshot shares the records and checks with its InputRecorder, the
+// recorder only ever appends to them so the snapshot only needs their lengths
+// at the time it was taken, see InputRecorder.snapshot.
+type RecorderSnapshot struct {
+	Recording    bool
+	Characters   []int
+	Frame        int
+	Records      []inputRecord
+	HashInterval int
+	Checks       []StateCheck
+}
+
+type GhostSnapshot struct {
+	Enabled      bool
+	Attempt      RecorderSnapshot
+	AttemptStart InputStateSnapshot
+	Discarded    bool
+	Best         []inputRecord
+	BestStart    InputStateSnapshot
+	BestFrames   int
+	Character    CharacterSnapshot
+	Input        InputStateSnapshot
+	// Run is the run that the ghost is racing, it is nil if the ghost is not
+	// racing
+	Run    []inputRecord
+	Cursor int
+}
+
+type TimerSnapshot struct {
+	Frames             int
+	Running            bool
+	SplitTimes         []int
+	ShownSplit         int
+	ShowSplitCountDown int
+}
+
+// Bytes serializes the snapshot, use SnapshotFromBytes to read it back.
+func (s *Snapshot) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func SnapshotFromBytes(data []byte) (*Snapshot, error) {
+	var s Snapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Snapshot captures the current simulation state.
+func (g *Game) Snapshot() *Snapshot {
+	s := &Snapshot{}
+	g.snapshotTo(s)
+	return s
+}
+
+// snapshotTo overwrites s with the current simulation state. It reuses the
+// memory of s's lists so the rewind buffer can take a snapshot every frame
+// without allocating.
+func (g *Game) snapshotTo(s *Snapshot) {
+	*s = Snapshot{
+		State:                g.state,
+		PrePlayCountDown:     g.prePlayCountDown,
+		PlayerDyingCountDown: g.playerDyingCountDown,
+		LosingSoundCountDown: g.losingSoundCountDown,
+		BarneyWinCountDown:   g.barneyWinCountDown,
+		PlayerWinCountDown:   g.playerWinCountDown,
+		IntroCountUp:         g.introCountUp,
+		CurrentIntroPCImage:  g.currentIntroPCImage,
+		IntroBarneyTalking:   g.introBarneyTalking,
+		Frame:                g.frame,
+		CameraTarget:         g.cameraTarget,
+		CameraBounds:         g.cameraBounds,
+		Checkpoint:           g.checkpoint,
+		Hint:                 g.hint,
+		HintCountDown:        g.hintCountDown,
+		AICursor:             g.aiPlayer.Cursor(),
+		AIStartFrame:         g.aiStartFrame,
+		Recorder:             g.recorder.snapshot(s.Recorder.Characters),
+		Ghost:                g.ghost.snapshot(s.Ghost.Attempt.Characters),
+		Timer:                g.timer.snapshot(s.Timer.SplitTimes),
+		Characters:           s.Characters[:0],
+		InputStates:          s.InputStates[:0],
+		TriggersInside:       s.TriggersInside[:0],
+		Collected:            s.Collected[:0],
+	}
+	for i := range g.characters {
+		s.Characters = append(s.Characters, g.characters[i].snapshot())
+		s.InputStates = append(s.InputStates, g.inputStates[i].snapshot())
+	}
+	if g.replayPlayer != nil {
+		s.ReplayCursor = g.replayPlayer.Cursor()
+	}
+	for i := range g.triggers {
+		s.TriggersInside = append(s.TriggersInside, g.triggers[i].inside)
+	}
+	for i := range g.collectibles {
+		s.Collected = append(s.Collected, g.collectibles[i].collected)
+	}
+}
+
+// Restore sets the simulation state to that of the snapshot. If the snapshot
+// does not fit the loaded level, e.g. because it was taken in another level or
+// by an older build, the game is left as it is and an error is returned.
+func (g *Game) Restore(s *Snapshot) error {
+	if err := g.checkSnapshot(s); err != nil {
+		return err
+	}
+	g.state = s.State
+	g.prePlayCountDown = s.PrePlayCountDown
+	g.playerDyingCountDown = s.PlayerDyingCountDown
+	g.losingSoundCountDown = s.LosingSoundCountDown
+	g.barneyWinCountDown = s.BarneyWinCountDown
+	g.playerWinCountDown = s.PlayerWinCountDown
+	g.introCountUp = s.IntroCountUp
+	g.currentIntroPCImage = s.CurrentIntroPCImage
+	g.introBarneyTalking = s.IntroBarneyTalking
+	g.frame = s.Frame
+	g.cameraTarget = s.CameraTarget
+	g.setCameraBounds(s.CameraBounds)
+	g.checkpoint = s.Checkpoint
+	g.hint = s.Hint
+	g.hintCountDown = s.HintCountDown
+	for i := range g.triggers {
+		g.triggers[i].inside = s.TriggersInside[i]
+	}
+	for i := range g.collectibles {
+		g.collectibles[i].collected = s.Collected[i]
+	}
+	g.aiPlayer.Seek(s.AICursor)
+	g.aiStartFrame = s.AIStartFrame
+	g.recorder.restore(s.Recorder)
+	g.ghost.restore(s.Ghost)
+	g.timer.restore(s.Timer)
+	for i := range g.characters {
+		g.characters[i].restore(s.Characters[i])
+		g.inputStates[i].restore(s.InputStates[i])
+	}
+	if g.replayPlayer != nil {
+		g.replayPlayer.Seek(s.ReplayCursor)
+	}
+	// the platforms' positions only depend on the frame
+	g.placePlatforms()
+	return nil
+}
+
+// checkSnapshot returns an error if the snapshot's lists do not have the
+// lengths that Restore needs for the loaded level.
+func (g *Game) checkSnapshot(s *Snapshot) error {
+	if len(s.Characters) != len(g.characters) || len(s.InputStates) != len(g.characters) {
+		return fmt.Errorf(
+			"snapshot has %v characters and %v input states, the game has %v characters",
+			len(s.Characters), len(s.InputStates), len(g.characters),
+		)
+	}
+	if len(s.TriggersInside) != len(g.triggers) {
+		return fmt.Errorf("snapshot has %v triggers, the level has %v",
+			len(s.TriggersInside), len(g.triggers))
+	}
+	if len(s.Collected) != len(g.collectibles) {
+		return fmt.Errorf("snapshot has %v collectibles, the level has %v",
+			len(s.Collected), len(g.collectibles))
+	}
+	// the timer shows the split time at ShownSplit
+	t := s.Timer
+	if len(t.SplitTimes) > len(g.timer.splits)+1 ||
+		len(t.SplitTimes) > 0 && (t.ShownSplit < 0 || t.ShownSplit >= len(t.SplitTimes)) ||
+		len(t.SplitTimes) == 0 && t.ShowSplitCountDown > 0 {
+		return fmt.Errorf("snapshot has %v split times showing split %v, the level has %v splits",
+			len(t.SplitTimes), t.ShownSplit, len(g.timer.splits)+1)
+	}
+	return nil
+}
+
+func (c *Character) snapshot() CharacterSnapshot {
+	return CharacterSnapshot{
+		Direction:     c.Direction,
+		Position:      c.Position,
+		LastPosition:  c.lastPosition,
+		SpeedX:        c.SpeedX,
+		SpeedY:        c.SpeedY,
+		SubX:          c.subX,
+		SubY:          c.subY,
+		InAir:         c.InAir,
+		WallSliding:   c.wallSliding,
+		CoyoteFrames:  c.coyoteFrames,
+		JumpBuffer:    c.jumpBuffer,
+		RunFrameIndex: c.runFrameIndex,
+		NextRunFrame:  c.nextRunFrame,
+		Params:        c.Params,
+		DropThrough:   append([]int(nil), c.dropThrough...),
+		DropFrames:    c.dropFrames,
+	}
+}
+
+func (c *Character) restore(s CharacterSnapshot) {
+	c.Direction = s.Direction
+	c.Position = s.Position
+	c.lastPosition = s.LastPosition
+	c.SpeedX = s.SpeedX
+	c.SpeedY = s.SpeedY
+	c.subX = s.SubX
+	c.subY = s.SubY
+	c.InAir = s.InAir
+	c.wallSliding = s.WallSliding
+	c.coyoteFrames = s.CoyoteFrames
+	c.jumpBuffer = s.JumpBuffer
+	c.runFrameIndex = s.RunFrameIndex
+	c.nextRunFrame = s.NextRunFrame
+	c.Params = s.Params
+	c.dropThrough = append([]int(nil), s.DropThrough...)
+	c.dropFrames = s.DropFrames
+}
+
+func (s *inputState) snapshot() InputStateSnapshot {
+	return InputStateSnapshot{
+		LeftDown:          s.leftDown,
+		RightDown:         s.rightDown,
+		JumpDown:          s.jumpDown,
+		MustJumpThisFrame: s.mustJumpThisFrame,
+		DownDown:          s.downDown,
+	}
+}
+
+func (s *inputState) restore(from InputStateSnapshot) {
+	s.leftDown = from.LeftDown
+	s.rightDown = from.RightDown
+	s.jumpDown = from.JumpDown
+	s.mustJumpThisFrame = from.MustJumpThisFrame
+	s.downDown = from.DownDown
+}
+
+// snapshot does not copy the records and checks, a game takes a snapshot every
+// frame and copying the growing lists every time would take quadratic time and
+// memory. Appending does not change the part of the lists that the snapshot
+// sees and Restart starts new lists. The character indices are copied into
+// characters, pass nil to allocate a new list.
+func (r *InputRecorder) snapshot(characters []int) RecorderSnapshot {
+	return RecorderSnapshot{
+		Recording:    r.recording,
+		Characters:   append(characters[:0], r.characters...),
+		Frame:        r.frame,
+		Records:      r.records,
+		HashInterval: r.hashInterval,
+		Checks:       r.checks,
+	}
+}
+
+// restore cuts the records and checks back to the snapshot's lengths. Their
+// capacity is cut as well so that the next append copies them instead of
+// overwriting records that newer snapshots still see.
+func (r *InputRecorder) restore(s RecorderSnapshot) {
+	r.recording = s.Recording
+	r.characters = append(r.characters[:0], s.Characters...)
+	r.frame = s.Frame
+	r.records = s.Records[:len(s.Records):len(s.Records)]
+	r.hashInterval = s.HashInterval
+	r.checks = s.Checks[:len(s.Checks):len(s.Checks)]
+}
+
+// snapshot copies the attempt's character indices into attemptCharacters, see
+// InputRecorder.snapshot.
+func (r *ghostRunner) snapshot(attemptCharacters []int) GhostSnapshot {
+	s := GhostSnapshot{
+		Enabled:      r.enabled,
+		Attempt:      r.attempt.snapshot(attemptCharacters),
+		AttemptStart: r.attemptStart.snapshot(),
+		Discarded:    r.discarded,
+		// the best run is never modified, only replaced, so it can be shared
+		Best:       r.best,
+		BestStart:  r.bestStart.snapshot(),
+		BestFrames: r.bestFrames,
+		Character:  r.char.snapshot(),
+		Input:      r.input.snapshot(),
+	}
+	if r.player != nil {
+		s.Run = r.player.records
+		s.Cursor = r.player.Cursor()
+	}
+	return s
+}
+
+func (r *ghostRunner) restore(s GhostSnapshot) {
+	r.enabled = s.Enabled
+	r.attempt.restore(s.Attempt)
+	r.attemptStart.restore(s.AttemptStart)
+	r.discarded = s.Discarded
+	r.best = s.Best
+	r.bestStart.restore(s.BestStart)
+	r.bestFrames = s.BestFrames
+	r.char.restore(s.Character)
+	r.input.restore(s.Input)
+	r.player = nil
+	if s.Run != nil {
+		r.player = NewInputPlayer(s.Run)
+		r.player.Seek(s.Cursor)
+	}
+}
+
+// snapshot copies the split times into splitTimes, pass nil to allocate a new
+// list.
+func (t *speedrunTimer) snapshot(splitTimes []int) TimerSnapshot {
+	return TimerSnapshot{
+		Frames:             t.frames,
+		Running:            t.running,
+		SplitTimes:         append(splitTimes[:0], t.splitTimes...),
+		ShownSplit:         t.shownSplit,
+		ShowSplitCountDown: t.showSplitCountDown,
+	}
+}
+
+func (t *speedrunTimer) restore(s TimerSnapshot) {
+	t.frames = s.Frames
+	t.running = s.Running
+	t.splitTimes = append(t.splitTimes[:0], s.SplitTimes...)
+	t.shownSplit = s.ShownSplit
+	t.showSplitCountDown = s.ShowSplitCountDown
+}
@@ -0,0 +1,51 @@
+// +build headless
+
+package main
+
+import "testing"
+
+func TestRestoreRejectsSnapshotOfOtherLevel(t *testing.T) {
+	game, _ := newTestGame(0)
+	for i := 0; i < 1000; i++ {
+		game.Update()
+	}
+	data, err := game.Snapshot().Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mismatches := map[string]func(s *Snapshot){
+		"collectibles": func(s *Snapshot) { s.Collected = append(s.Collected, true) },
+		"triggers":     func(s *Snapshot) { s.TriggersInside = append(s.TriggersInside, true) },
+		"characters":   func(s *Snapshot) { s.Characters = s.Characters[:1] },
+		"input states": func(s *Snapshot) { s.InputStates = nil },
+		"split times":  func(s *Snapshot) { s.Timer.SplitTimes = make([]int, 10) },
+		"shown split": func(s *Snapshot) {
+			s.Timer.SplitTimes = nil
+			s.Timer.ShowSplitCountDown = 1
+		},
+	}
+	for name, mismatch := range mismatches {
+		s, err := SnapshotFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mismatch(s)
+		frame := game.frame
+		s.Frame = frame + 1
+		if err := game.Restore(s); err == nil {
+			t.Errorf("%v: restoring a mismatched snapshot is no error", name)
+		}
+		if game.frame != frame {
+			t.Errorf("%v: a mismatched snapshot was partly restored", name)
+		}
+	}
+
+	s, err := SnapshotFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.Restore(s); err != nil {
+		t.Errorf("restoring the game's own snapshot: %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Split is a named region in a level, the time at which the hero first enters
+// it is taken as a split time. Splits must be reached in order.
+type Split struct {
+	Name   string
+	Bounds Rectangle
+}
+
+// LevelSplits are the split regions per level ID. Reaching the goal is always
+// the last split, it does not have to be listed here.
+var LevelSplits = map[string][]Split{
+	"level1": {
+		{"platforms", Rectangle{2900, -2000, 200, 2537}},
+		{"big rock", Rectangle{4380, -2000, 178, 2413}},
+		{"stairs", Rectangle{6581, -2000, 382, 2322}},
+		{"tree tops", Rectangle{7767, -1500, 1000, 818}},
+	},
+}
+
+const (
+	goalSplitName = "goal"
+	// SplitDisplayDuration is the number of frames that a split and its delta
+	// to the personal best are shown after reaching it
+	SplitDisplayDuration = 3 * UpdatesPerSecond
+)
+
+// PersonalBest holds the split times of the fastest run through a level, in
+// frames since the race started. The last split is the goal.
+type PersonalBest struct {
+	Splits []int
+}
+
+func (pb *PersonalBest) Total() int {
+	return pb.Splits[len(pb.Splits)-1]
+}
+
+// personalBests maps level IDs to the best run in that level.
+type personalBests map[string]*PersonalBest
+
+// personalBestsFile is the path to the user config file that stores the
+// personal bests.
+func personalBestsFile() string {
+	return userConfigFile("personal_bests.json")
+}
+
+// userConfigFile returns the path to the game's config file with the name, it
+// is empty if there is no user config directory.
+func userConfigFile(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gophette", name)
+}
+
+// loadPersonalBests returns an empty set of bests if the file does not exist
+// or can not be read. Bests that do not have a time for every split of their
+// level are dropped.
+func loadPersonalBests(path string) personalBests {
+	bests := make(personalBests)
+	if path == "" {
+		return bests
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return bests
+	}
+	if err := json.Unmarshal(data, &bests); err != nil {
+		fmt.Println("error reading personal bests:", err)
+		return make(personalBests)
+	}
+	for id, best := range bests {
+		if best == nil || len(best.Splits) != len(LevelSplits[id])+1 {
+			delete(bests, id)
+		}
+	}
+	return bests
+}
+
+func (bests personalBests) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(bests, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// speedrunTimer measures the race time and the split times of the hero's
+// current run and compares them to the personal best in the level.
+type speedrunTimer struct {
+	levelID  string
+	splits   []Split
+	bests    personalBests
+	bestPath string
+	// saveBests is false when the run is not played by the user, e.g. when
+	// watching a replay
+	saveBests bool
+
+	frames     int
+	running    bool
+	splitTimes []int
+	// shownSplit is the index of the split to show, it is shown for
+	// showSplitCountDown frames
+	shownSplit         int
+	showSplitCountDown int
+}
+
+func newSpeedrunTimer(levelID string, bestsPath string) *speedrunTimer {
+	return &speedrunTimer{
+		levelID:   levelID,
+		splits:    LevelSplits[levelID],
+		bests:     loadPersonalBests(bestsPath),
+		bestPath:  bestsPath,
+		saveBests: true,
+	}
+}
+
+// reset is called when a new race starts, the timer starts running with the
+// first call to update.
+func (t *speedrunTimer) reset() {
+	t.frames = 0
+	t.running = false
+	t.splitTimes = t.splitTimes[:0]
+	t.showSplitCountDown = 0
+}
+
+// update is called once per frame while playing, frame is the current race
+// frame and hero is the hero's position.
+func (t *speedrunTimer) update(frame int, hero Rectangle) {
+	t.running = true
+	t.frames = frame
+	if t.showSplitCountDown > 0 {
+		t.showSplitCountDown--
+	}
+	next := len(t.splitTimes)
+	if next < len(t.splits) && t.splits[next].Bounds.Overlaps(hero) {
+		t.split(frame)
+	}
+}
+
+// finish stops the timer when the hero reaches the goal after the given
+// number of frames. A new personal best is saved.
+func (t *speedrunTimer) finish(frame int) {
+	if !t.running {
+		return
+	}
+	t.running = false
+	t.frames = frame
+	// splits that were skipped get the goal time
+	for len(t.splitTimes) < len(t.splits) {
+		t.splitTimes = append(t.splitTimes, frame)
+	}
+	t.split(frame)
+
+	best := t.bests[t.levelID]
+	if t.saveBests && (best == nil || frame < best.Total()) {
+		splits := make([]int, len(t.splitTimes))
+		copy(splits, t.splitTimes)
+		t.bests[t.levelID] = &PersonalBest{Splits: splits}
+		if err := t.bests.save(t.bestPath); err != nil {
+			fmt.Println("error saving personal bests:", err)
+		}
+	}
+}
+
+func (t *speedrunTimer) split(frame int) {
+	t.splitTimes = append(t.splitTimes, frame)
+	t.shownSplit = len(t.splitTimes) - 1
+	t.showSplitCountDown = SplitDisplayDuration
+}
+
+func (t *speedrunTimer) splitName(i int) string {
+	if i < len(t.splits) {
+		return t.splits[i].Name
+	}
+	return goalSplitName
+}
+
+// delta returns the difference of the split time to the personal best, in
+// frames; ok is false if there is no personal best for the split.
+func (t *speedrunTimer) delta(i int) (delta int, ok bool) {
+	best := t.bests[t.levelID]
+	if best == nil || i >= len(best.Splits) || len(best.Splits) != len(t.splits)+1 {
+		return 0, false
+	}
+	return t.splitTimes[i] - best.Splits[i], true
+}
+
+const hudPixelSize = 4
+
+func (t *speedrunTimer) render(graphics Graphics) {
+	x, y := 20, 20
+	drawText(graphics, formatFrames(t.frames), x, y, hudPixelSize, 255, 255, 255)
+	_, h := textSize("0", hudPixelSize)
+	y += h + hudPixelSize*2
+
+	if best := t.bests[t.levelID]; best != nil {
+		drawText(graphics, "PB "+formatFrames(best.Total()),
+			x, y, hudPixelSize/2, 200, 200, 200)
+		y += h/2 + hudPixelSize
+	}
+
+	if t.showSplitCountDown > 0 || (!t.running && len(t.splitTimes) > 0) {
+		i := t.shownSplit
+		text := t.splitName(i) + " " + formatFrames(t.splitTimes[i])
+		drawText(graphics, text, x, y, hudPixelSize/2, 255, 255, 255)
+		if delta, ok := t.delta(i); ok {
+			w, _ := textSize(text+" ", hudPixelSize/2)
+			if delta == 0 {
+				drawText(graphics, formatFrames(0),
+					x+w, y, hudPixelSize/2, 255, 255, 255)
+			} else if delta < 0 {
+				drawText(graphics, "-"+formatFrames(-delta),
+					x+w, y, hudPixelSize/2, 0, 255, 0)
+			} else {
+				drawText(graphics, "+"+formatFrames(delta),
+					x+w, y, hudPixelSize/2, 255, 64, 64)
+			}
+		}
+	}
+}
+
+// formatFrames formats a number of frames as a time m:ss.hh
+func formatFrames(frames int) string {
+	hundredths := frames * 100 / UpdatesPerSecond
+	return fmt.Sprintf(
+		"%d:%02d.%02d",
+		hundredths/6000,
+		hundredths/100%60,
+		hundredths%100,
+	)
+}
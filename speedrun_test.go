@@ -0,0 +1,36 @@
+// +build headless
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPersonalBestsDropsBrokenSplits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gophette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "personal_bests.json")
+	data := `{
+		"level1": {"Splits": []},
+		"level2": {"Splits": [1, 2]},
+		"level3": null,
+		"level4": {"Splits": [100]}
+	}`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bests := loadPersonalBests(path)
+	if len(bests) != 1 || bests["level4"] == nil {
+		t.Fatalf("want only the best of level4, have %v", bests)
+	}
+	if total := bests["level4"].Total(); total != 100 {
+		t.Errorf("level4 total is %v, want 100", total)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+)
+
+// SimState is the part of the simulation state that is compared to detect
+// desyncs between a recording and its playback.
+type SimState struct {
+	Frame       int
+	State       GameState
+	Characters  [2]CharacterSnapshot
+	InputStates [2]InputStateSnapshot
+	AICursor    int
+}
+
+// StateCheck is stored in replays every few frames, see
+// InputRecorder.SetHashInterval. The full state is stored along with its hash
+// so a desync can be explained, not only detected.
+type StateCheck struct {
+	Hash  uint64
+	State SimState
+}
+
+func (g *Game) simState() SimState {
+	s := SimState{
+		Frame:    g.frame,
+		State:    g.state,
+		AICursor: g.aiPlayer.Cursor(),
+	}
+	for i := range g.characters {
+		s.Characters[i] = g.characters[i].snapshot()
+		s.InputStates[i] = g.inputStates[i].snapshot()
+	}
+	return s
+}
+
+func (s SimState) Hash() uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%+v", s)
+	return h.Sum64()
+}
+
+// Diff lists all fields that differ between s and o, one line per field.
+func (s SimState) Diff(o SimState) []string {
+	var diffs []string
+	var diff func(path string, a, b reflect.Value)
+	diff = func(path string, a, b reflect.Value) {
+		switch a.Kind() {
+		case reflect.Struct:
+			for i := 0; i < a.NumField(); i++ {
+				name := a.Type().Field(i).Name
+				diff(path+"."+name, a.Field(i), b.Field(i))
+			}
+		case reflect.Slice:
+			if a.Len() != b.Len() {
+				diffs = append(diffs, fmt.Sprintf(
+					"%s: %v != %v", path[1:], a.Interface(), b.Interface(),
+				))
+				return
+			}
+			fallthrough
+		case reflect.Array:
+			for i := 0; i < a.Len(); i++ {
+				diff(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i))
+			}
+		default:
+			if a.Interface() != b.Interface() {
+				diffs = append(diffs, fmt.Sprintf(
+					"%s: %v != %v", path[1:], a.Interface(), b.Interface(),
+				))
+			}
+		}
+	}
+	diff("", reflect.ValueOf(s), reflect.ValueOf(o))
+	return diffs
+}
+
+// Desync describes the first state check where a playback differs from the
+// recording.
+type Desync struct {
+	Frame     int
+	Recorded  SimState
+	Simulated SimState
+}
+
+func (d *Desync) String() string {
+	text := fmt.Sprintf("desync at frame %v:", d.Frame)
+	for _, line := range d.Recorded.Diff(d.Simulated) {
+		text += "\n\trecorded != simulated " + line
+	}
+	return text
+}
+
+// VerifyReplay simulates the game, which must be set up to watch the replay,
+// and compares its state to the state checks in the replay. It returns nil if
+// all checks match. An error is returned if the replay has no checks or the
+// simulation does not reach the last check.
+func VerifyReplay(g *Game, replay *Replay) (*Desync, error) {
+	if len(replay.Checks) == 0 {
+		return nil, fmt.Errorf("the replay contains no state checks")
+	}
+
+	// allow for the intro and the count down before the race starts
+	maxUpdates := IntroDuration + PrePlayFrameDelay + replay.FrameCount + 1000
+	next := 0
+	for i := 0; i < maxUpdates && next < len(replay.Checks); i++ {
+		g.Update()
+		check := replay.Checks[next]
+		if g.frame == check.State.Frame {
+			state := g.simState()
+			if state.Hash() != check.Hash {
+				return &Desync{check.State.Frame, check.State, state}, nil
+			}
+			next++
+		}
+	}
+
+	if next < len(replay.Checks) {
+		return nil, fmt.Errorf(
+			"the simulation ended before frame %v", replay.Checks[next].State.Frame,
+		)
+	}
+	return nil, nil
+}
@@ -0,0 +1,2 @@
+go get github.com/gonutz/blob
+go test -tags headless %*
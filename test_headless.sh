@@ -0,0 +1,4 @@
+#!/bin/bash
+# the tests run with the headless backend, it needs neither SDL2 nor DirectX
+go get github.com/gonutz/blob
+go test -tags headless "$@"
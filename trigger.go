@@ -0,0 +1,181 @@
+package main
+
+// LevelTrigger is an invisible rectangle that fires its Actions when the
+// character with the given index (0 is the hero, 1 is Barney) enters or leaves
+// it. A character is inside a trigger when the trigger contains her collision
+// rectangle completely.
+type LevelTrigger struct {
+	X, Y, W, H int
+	Character  int
+	Event      TriggerEvent
+	Actions    []TriggerAction
+}
+
+type TriggerEvent int
+
+const (
+	OnEnter TriggerEvent = iota
+	OnLeave
+)
+
+// String returns the constant's name, the level editor writes these into the
+// level files.
+func (e TriggerEvent) String() string {
+	switch e {
+	case OnEnter:
+		return "OnEnter"
+	case OnLeave:
+		return "OnLeave"
+	default:
+		return "unknown trigger event"
+	}
+}
+
+// TriggerAction is one thing that a trigger does. Which of the fields are used
+// depends on the Kind, see the ActionKind constants.
+type TriggerAction struct {
+	Kind   ActionKind
+	ID     string
+	Bounds Rectangle
+	Point  Point
+}
+
+type ActionKind int
+
+const (
+	// PlaySound plays the sound with the resource ID
+	PlaySound ActionKind = iota
+	// SetCameraBounds keeps the camera inside Bounds
+	SetCameraBounds
+	// SetCheckpoint has the hero respawn at Point, which is her bottom center,
+	// in levels with the respawn mode RespawnAtCheckpoint
+	SetCheckpoint
+	// ShowHint shows the text in ID for HintDuration frames
+	ShowHint
+	// StartCutScene plays the cut-scene with the ID, see cutScenes
+	StartCutScene
+	// HeroWins ends the race with the hero winning, unless it is decided
+	HeroWins
+	// BarneyWins ends the race with Barney winning, unless it is decided
+	BarneyWins
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case PlaySound:
+		return "PlaySound"
+	case SetCameraBounds:
+		return "SetCameraBounds"
+	case SetCheckpoint:
+		return "SetCheckpoint"
+	case ShowHint:
+		return "ShowHint"
+	case StartCutScene:
+		return "StartCutScene"
+	case HeroWins:
+		return "HeroWins"
+	case BarneyWins:
+		return "BarneyWins"
+	default:
+		return "unknown action"
+	}
+}
+
+// HintDuration is the number of frames that a hint is shown.
+const HintDuration = 4 * UpdatesPerSecond
+
+// cutScenes start the cut-scenes that triggers refer to by ID.
+var cutScenes = map[string]func(g *Game){
+	"intro": (*Game).startIntro,
+}
+
+type trigger struct {
+	LevelTrigger
+	// sounds has the loaded sound for every PlaySound action
+	sounds []Sound
+	inside bool
+}
+
+func (g *Game) loadTriggers(assets AssetLoader, level *Level) {
+	g.triggers = make([]trigger, len(level.Triggers))
+	for i := range level.Triggers {
+		t := &g.triggers[i]
+		t.LevelTrigger = level.Triggers[i]
+		t.sounds = make([]Sound, len(t.Actions))
+		for j, action := range t.Actions {
+			if action.Kind == PlaySound {
+				t.sounds[j] = assets.LoadSound(action.ID)
+			}
+		}
+	}
+}
+
+// updateTriggers fires all triggers that a character entered or left since the
+// last call. The triggers are evaluated in the order of the level data.
+func (g *Game) updateTriggers() {
+	for i := range g.triggers {
+		t := &g.triggers[i]
+		bounds := Rectangle{t.X, t.Y, t.W, t.H}
+		inside := bounds.Contains(g.characters[t.Character].Position)
+		fire := t.Event == OnEnter && inside && !t.inside ||
+			t.Event == OnLeave && !inside && t.inside
+		t.inside = inside
+		if fire {
+			for j := range t.Actions {
+				g.runAction(&t.Actions[j], t.sounds[j])
+			}
+		}
+	}
+}
+
+func (g *Game) resetTriggers() {
+	for i := range g.triggers {
+		g.triggers[i].inside = false
+	}
+}
+
+func (g *Game) runAction(a *TriggerAction, sound Sound) {
+	decided := g.state == PlayerWinning || g.state == PlayerRealizingLoss
+	switch a.Kind {
+	case PlaySound:
+		sound.PlayOnce()
+	case SetCameraBounds:
+		g.setCameraBounds(a.Bounds)
+	case SetCheckpoint:
+		g.checkpoint = a.Point
+	case ShowHint:
+		g.hint = a.ID
+		g.hintCountDown = HintDuration
+	case StartCutScene:
+		if start, ok := cutScenes[a.ID]; ok {
+			start(g)
+		}
+	case HeroWins:
+		if !decided {
+			g.winningSound.PlayOnce()
+			g.ghost.finishAttempt(g.frame)
+			g.timer.finish(g.frame)
+			g.collections.finish(g.levelID, g.collectedCount())
+			g.playerWinCountDown = PlayerWinDelay
+			g.state = PlayerWinning
+		}
+	case BarneyWins:
+		if !decided {
+			g.losingSound.PlayOnce()
+			g.collections.finish(g.levelID, g.collectedCount())
+			g.state = PlayerRealizingLoss
+			g.losingSoundCountDown = LosingSoundDelay
+		}
+	}
+}
+
+func (g *Game) setCameraBounds(bounds Rectangle) {
+	g.cameraBounds = bounds
+	g.camera.SetBounds(bounds)
+}
+
+func (g *Game) renderHint() {
+	if g.hintCountDown > 0 {
+		drawText(g.graphics, g.hint, 20, 180, hudPixelSize, 255, 255, 255)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+// LevelZone is a rectangle that changes how the characters that overlap it
+// move. When a character is in more than one zone, they are applied in the
+// order of the level data: the gravity is scaled by each zone in turn, each
+// speed cap is applied and the winds add up.
+type LevelZone struct {
+	X, Y, W, H int
+	// GravityPercent scales HighGravity and LowGravity, 100 keeps them as
+	// they are and 0 means no gravity at all
+	GravityPercent int
+	// MaxSpeedX and MaxSpeedY cap the speeds in both directions, in SubPixels
+	// per frame; 0 means no cap
+	MaxSpeedX int
+	MaxSpeedY int
+	// Wind pushes the characters in SubPixels per frame, to the right if it
+	// is positive; it moves them without changing their speed
+	Wind int
+}
+
+func (z *LevelZone) bounds() Rectangle {
+	return Rectangle{z.X, z.Y, z.W, z.H}
+}
+
+func (g *Game) zoneGravity(bounds Rectangle, gravity int) int {
+	for i := range g.zones {
+		if g.zones[i].bounds().Overlaps(bounds) {
+			gravity = gravity * g.zones[i].GravityPercent / 100
+		}
+	}
+	return gravity
+}
+
+func (g *Game) capZoneSpeed(bounds Rectangle, char *Character) {
+	for i := range g.zones {
+		z := &g.zones[i]
+		if !z.bounds().Overlaps(bounds) {
+			continue
+		}
+		if z.MaxSpeedX != 0 {
+			char.SpeedX = clamp(char.SpeedX, -z.MaxSpeedX, z.MaxSpeedX)
+		}
+		if z.MaxSpeedY != 0 {
+			char.SpeedY = clamp(char.SpeedY, -z.MaxSpeedY, z.MaxSpeedY)
+		}
+	}
+}
+
+func (g *Game) zoneWind(bounds Rectangle) int {
+	wind := 0
+	for i := range g.zones {
+		if g.zones[i].bounds().Overlaps(bounds) {
+			wind += g.zones[i].Wind
+		}
+	}
+	return wind
+}
+
+func clamp(x, lo, hi int) int {
+	return max(lo, min(x, hi))
+}